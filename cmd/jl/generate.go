@@ -0,0 +1,361 @@
+// Copyright (C) 2022 CGI France
+//
+// This file is part of JL.
+//
+// JL is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// JL is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with JL.  If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"math/big"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/cgi-fr/jsonline/pkg/jsonline"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+const jsonlinePackage = "github.com/cgi-fr/jsonline/pkg/jsonline"
+
+// initialisms are written in upper case in generated identifiers.
+//
+//nolint:gochecknoglobals
+var initialisms = map[string]bool{
+	"api": true, "cidr": true, "csv": true, "html": true, "http": true, "id": true, "ip": true, "json": true,
+	"sql": true, "ttl": true, "uri": true, "url": true, "utc": true, "uuid": true, "xml": true,
+}
+
+func newGenerateCommand() *cobra.Command {
+	cmd := &cobra.Command{ //nolint:exhaustivestruct
+		Use:   "generate",
+		Short: "Generate code from row definitions",
+		Args:  cobra.NoArgs,
+	}
+
+	cmd.AddCommand(newGenerateGoCommand())
+
+	return cmd
+}
+
+func newGenerateGoCommand() *cobra.Command {
+	tf := templateFlags{
+		template:     "{}",
+		filename:     "./row.yml",
+		extraColumns: "",
+		tz:           "",
+		assumeTz:     "",
+	}
+
+	pkg, typename := "main", "Row"
+
+	cmd := &cobra.Command{ //nolint:exhaustivestruct
+		Use:   "go",
+		Short: "Print Go struct types, a template constructor and a typed importer of the input row template",
+		Long: `Print Go struct types matching the input row template, a constructor of the template and a typed importer.
+Columns without type are declared with the Go type of their format (e.g. time.Time for dates), the output is gofmt'd.`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := generateGo(cmd, pkg, typename); err != nil {
+				log.Error().Err(err).Msg("failed to generate Go code")
+				os.Exit(1)
+			}
+		},
+		Example: fmt.Sprintf(`  %s generate go -f row.yml --package model --type Customer >customer.go`, name),
+	}
+
+	addTemplateFlags(cmd, &tf)
+
+	cmd.Flags().StringVar(&pkg, "package", pkg, "name of the package of the generated code")
+	cmd.Flags().StringVar(&typename, "type", typename, "name of the generated struct type")
+
+	return cmd
+}
+
+func generateGo(cmd *cobra.Command, pkg string, typename string) error {
+	ti, _, err := createTemplate(cmd)
+	if err != nil {
+		return err
+	}
+
+	gen := &goGenerator{
+		imports: map[string]bool{"io": true, "strings": true, jsonlinePackage: true},
+		structs: []string{},
+	}
+
+	def := jsonline.NewRowDefinition(ti)
+	gen.generateStruct(typename, typename+" is a row of the row definition.", ti.Columns(), def.Columns)
+
+	source, err := gen.source(pkg, typename, def)
+	if err != nil {
+		return err
+	}
+
+	_, err = os.Stdout.Write(source)
+
+	return err
+}
+
+// goGenerator collects the struct types and the imports of the generated code.
+type goGenerator struct {
+	imports map[string]bool
+	structs []string
+}
+
+// generateStruct declares the struct type of a row, columns without rawtype get the rawtype of the Go type of their
+// field in the definition, so that the rows of the template hold values of the field types.
+func (g *goGenerator) generateStruct(typename string, doc string, columns []jsonline.Column,
+	defs []jsonline.ColumnDefinition) {
+	index := len(g.structs)
+	g.structs = append(g.structs, "")
+
+	decl := &strings.Builder{}
+	fmt.Fprintf(decl, "// %s\ntype %s struct {\n", doc, typename)
+
+	used := map[string]bool{}
+
+	for i, column := range columns {
+		field := fieldName(column.Name, used)
+		tag := column.Name
+
+		var gotype string
+
+		if column.Template != nil {
+			gotype = typename + field
+			g.generateStruct(gotype, fmt.Sprintf("%s is the %s sub row of %s.", gotype, column.Name, typename),
+				column.Template.Columns(), defs[i].Columns)
+		} else {
+			rawtype := column.RawType
+			if rawtype == nil {
+				rawtype = defaultRawType(column.Format)
+
+				// string formats already hold strings
+				if _, isString := rawtype.(string); rawtype != nil && !isString {
+					defs[i].Input = jsonline.Descriptor(column.Format, rawtype, column.Options...)
+				}
+			}
+
+			gotype = g.typeName(rawtype)
+
+			if column.Format != jsonline.Auto {
+				tag += ",format=" + jsonline.Descriptor(column.Format, nil, column.Options...)
+			}
+		}
+
+		if column.Array {
+			gotype = "[]" + gotype
+		}
+
+		fmt.Fprintf(decl, "%s %s `jsonline:%q`\n", field, gotype, tag)
+	}
+
+	decl.WriteString("}\n")
+
+	g.structs[index] = decl.String()
+}
+
+// typeName returns the Go name of the type of a rawtype and records the packages to import.
+func (g *goGenerator) typeName(rawtype jsonline.RawType) string {
+	if rawtype == nil {
+		return "interface{}"
+	}
+
+	t := reflect.TypeOf(rawtype)
+
+	for elem := t; ; elem = elem.Elem() {
+		if elem.PkgPath() != "" {
+			g.imports[elem.PkgPath()] = true
+		}
+
+		if kind := elem.Kind(); kind != reflect.Ptr && kind != reflect.Slice && kind != reflect.Array {
+			break
+		}
+	}
+
+	result := t.String()
+	if strings.HasSuffix(result, "]uint8") {
+		result = strings.TrimSuffix(result, "uint8") + "byte"
+	}
+
+	return result
+}
+
+func (g *goGenerator) source(pkg string, typename string, def *jsonline.RowDefinition) ([]byte, error) {
+	definition := &bytes.Buffer{}
+
+	encoder := yaml.NewEncoder(definition)
+	encoder.SetIndent(2) //nolint:gomnd
+
+	if err := encoder.Encode(def); err != nil {
+		return nil, fmt.Errorf("%w", err)
+	}
+
+	if err := encoder.Close(); err != nil {
+		return nil, fmt.Errorf("%w", err)
+	}
+
+	literal := "`" + definition.String() + "`"
+	if strings.Contains(definition.String(), "`") {
+		literal = strconv.Quote(definition.String())
+	}
+
+	src := &strings.Builder{}
+	fmt.Fprintf(src, "// Code generated by jl generate go. DO NOT EDIT.\n\npackage %s\n\n", pkg)
+	src.WriteString(g.importDecl())
+	src.WriteString(strings.Join(g.structs, "\n"))
+	fmt.Fprintf(src, goHelpers, typename, jsonline.LcFirst(typename), literal)
+
+	result, err := format.Source([]byte(src.String()))
+	if err != nil {
+		return nil, fmt.Errorf("%w", err)
+	}
+
+	return result, nil
+}
+
+// importDecl lists the standard packages first, then the other packages, in alphabetical order.
+func (g *goGenerator) importDecl() string {
+	std, others := []string{}, []string{}
+
+	for path := range g.imports {
+		if strings.Contains(strings.Split(path, "/")[0], ".") {
+			others = append(others, strconv.Quote(path))
+		} else {
+			std = append(std, strconv.Quote(path))
+		}
+	}
+
+	sort.Strings(std)
+	sort.Strings(others)
+
+	return "import (\n" + strings.Join(std, "\n") + "\n\n" + strings.Join(others, "\n") + "\n)\n\n"
+}
+
+// goHelpers declares the template constructor and the typed importer, arguments are the name of the struct type, the
+// same name with a lowercase first letter and the literal of the row definition.
+const goHelpers = `
+// %[2]sDefinition is the row definition of %[1]s.
+const %[2]sDefinition = %[3]s
+
+// New%[1]sTemplate returns the template of %[1]s.
+func New%[1]sTemplate() (jsonline.Template, error) {
+	def, err := jsonline.ReadRowDefinition(strings.NewReader(%[2]sDefinition))
+	if err != nil {
+		return nil, err
+	}
+
+	template, _, err := def.Templates()
+
+	return template, err
+}
+
+// %[1]sImporter reads %[1]s values from JSON lines.
+type %[1]sImporter struct {
+	importer jsonline.Importer
+}
+
+// New%[1]sImporter returns an importer of %[1]s values reading JSON lines from r.
+func New%[1]sImporter(r io.Reader) (*%[1]sImporter, error) {
+	template, err := New%[1]sTemplate()
+	if err != nil {
+		return nil, err
+	}
+
+	return &%[1]sImporter{importer: template.GetImporter(r)}, nil
+}
+
+// Import reads the next line, it returns false when there is no more line to read.
+func (i *%[1]sImporter) Import() bool {
+	return i.importer.Import()
+}
+
+// Get returns the %[1]s value of the last line read.
+func (i *%[1]sImporter) Get() (%[1]s, error) {
+	var result %[1]s
+
+	row, err := i.importer.GetRow()
+	if err != nil {
+		return result, err
+	}
+
+	err = row.MapToE(&result)
+
+	return result, err
+}
+`
+
+// defaultRawType returns the rawtype of the Go type used for columns of the format declared without rawtype.
+//
+//nolint:cyclop
+func defaultRawType(f jsonline.Format) jsonline.RawType {
+	switch f {
+	case jsonline.String, jsonline.UUID, jsonline.IPv4, jsonline.IPv6, jsonline.CIDR, jsonline.URL, jsonline.Email:
+		return ""
+	case jsonline.Numeric:
+		return float64(0)
+	case jsonline.Boolean:
+		return false
+	case jsonline.Binary:
+		return []byte{}
+	case jsonline.Date, jsonline.DateTime, jsonline.Timestamp, jsonline.TimestampMilli, jsonline.TimestampMicro,
+		jsonline.TimestampNano:
+		return time.Time{}
+	case jsonline.Duration:
+		return time.Duration(0)
+	case jsonline.Decimal:
+		return (*big.Rat)(nil)
+	default:
+		return nil
+	}
+}
+
+// fieldName returns the exported Go name of a column, names already used get a numeric suffix.
+func fieldName(column string, used map[string]bool) string {
+	words := strings.FieldsFunc(column, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	result := ""
+
+	for _, word := range words {
+		if initialisms[strings.ToLower(word)] {
+			result += strings.ToUpper(word)
+		} else {
+			runes := []rune(word)
+			result += string(unicode.ToUpper(runes[0])) + string(runes[1:])
+		}
+	}
+
+	if result == "" || !unicode.IsLetter([]rune(result)[0]) {
+		result = "Column" + result
+	}
+
+	name := result
+	for i := 2; used[name]; i++ {
+		name = result + strconv.Itoa(i)
+	}
+
+	used[name] = true
+
+	return name
+}
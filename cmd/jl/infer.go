@@ -0,0 +1,130 @@
+// Copyright (C) 2022 CGI France
+//
+// This file is part of JL.
+//
+// JL is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// JL is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with JL.  If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/cgi-fr/jsonline/pkg/jsonline"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+const defaultInferLines = 100
+
+func newInferCommand() *cobra.Command {
+	lines := defaultInferLines
+
+	cmd := &cobra.Command{ //nolint:exhaustivestruct
+		Use:   "infer",
+		Short: "Infer a row definition from sample JSON lines",
+		Long: `Scan JSON lines from standard input and print the row definition (row.yml) that matches every scanned line.
+Columns with mixed types and columns with null values are reported as comments.`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := infer(lines); err != nil {
+				log.Error().Err(err).Msg("failed to infer row definition")
+				os.Exit(1)
+			}
+		},
+		Example: fmt.Sprintf(`  %s infer -n 1000 <sample.jsonl >row.yml`, name),
+	}
+
+	cmd.Flags().IntVarP(&lines, "lines", "n", lines, "number of lines to scan, 0 to scan every line")
+
+	return cmd
+}
+
+func infer(lines int) error {
+	_, inference, err := jsonline.InferTemplate(jsonline.NewImporter(os.Stdin), lines)
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+
+	def := jsonline.NewRowDefinition(inference.Template())
+
+	node := &yaml.Node{} //nolint:exhaustivestruct
+	if err := node.Encode(def); err != nil {
+		return fmt.Errorf("%w", err)
+	}
+
+	commentColumns(node, inference.Columns)
+
+	encoder := yaml.NewEncoder(os.Stdout)
+	encoder.SetIndent(2) //nolint:gomnd
+
+	if err := encoder.Encode(node); err != nil {
+		return fmt.Errorf("%w", err)
+	}
+
+	log.Info().Int("lines", inference.Lines).Int("columns", len(inference.Columns)).Msg("row definition inferred")
+
+	return encoder.Close()
+}
+
+// commentColumns adds the mixed types and the ratio of nulls as comments on the name of the columns.
+func commentColumns(node *yaml.Node, columns []*jsonline.InferredColumn) {
+	sequence := mappingValue(node, "columns")
+	if sequence == nil {
+		return
+	}
+
+	for i, column := range columns {
+		if i >= len(sequence.Content) {
+			break
+		}
+
+		comments := []string{}
+
+		switch {
+		case len(column.Types) > 1:
+			comments = append(comments, "mixed types: "+strings.Join(column.Types, ", "))
+			log.Warn().Str("column", column.Name).Strs("types", column.Types).Msg("mixed types")
+		case len(column.Items) > 1:
+			comments = append(comments, "mixed types in array: "+strings.Join(column.Items, ", "))
+			log.Warn().Str("column", column.Name).Strs("types", column.Items).Msg("mixed types in array")
+		}
+
+		if column.Nulls > 0 {
+			comments = append(comments, fmt.Sprintf("nulls: %.0f%%", column.NullRatio()*100)) //nolint:gomnd
+		}
+
+		if value := mappingValue(sequence.Content[i], "name"); value != nil && len(comments) > 0 {
+			value.LineComment = strings.Join(comments, ", ")
+		}
+
+		commentColumns(sequence.Content[i], column.Columns)
+	}
+}
+
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+
+	return nil
+}
@@ -19,6 +19,7 @@
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"runtime"
@@ -43,8 +44,11 @@ type globalFlags struct {
 }
 
 type templateFlags struct {
-	template string
-	filename string
+	template     string
+	filename     string
+	extraColumns string
+	tz           string
+	assumeTz     string
 }
 
 type RootCommand struct {
@@ -77,8 +81,11 @@ func NewRootCommand() (*RootCommand, error) {
 	}
 
 	tf := templateFlags{
-		template: "{}",
-		filename: "./row.yml",
+		template:     "{}",
+		filename:     "./row.yml",
+		extraColumns: "",
+		tz:           "",
+		assumeTz:     "",
 	}
 
 	rootCmd.PersistentFlags().StringVarP(&gf.verbosity, "verbosity", "v", gf.verbosity,
@@ -90,15 +97,14 @@ func NewRootCommand() (*RootCommand, error) {
 
 	rootCmd.PersistentFlags().SortFlags = false
 
-	//nolint:lll
-	rootCmd.Flags().StringVarP(&tf.template, "template", "t", tf.template,
-		`row template definition (-t {"name":"format"} or -t {"name":"format(type)"}) or -t {"name":"format(type):format"})`+"\n"+
-			`possible formats : string, numeric, boolean, binary, datetime, time, timestamp, auto, hidden`+"\n"+
-			`possible types : int, int64, int32, int16, int8, uint, uint64, uint32, uint16, uint8, float64, float32, bool, byte, rune, string, []byte, time.Time, json.Number`)
-	rootCmd.Flags().StringVarP(&tf.filename, "filename", "f", tf.filename, "name of row template filename")
+	addTemplateFlags(&rootCmd, &tf)
 
 	rootCmd.Flags().SortFlags = false
 
+	rootCmd.AddCommand(newInferCommand())
+	rootCmd.AddCommand(newSchemaCommand())
+	rootCmd.AddCommand(newGenerateCommand())
+
 	if err := bindViper(rootCmd); err != nil {
 		return nil, err
 	}
@@ -106,6 +112,28 @@ func NewRootCommand() (*RootCommand, error) {
 	return &RootCommand{rootCmd}, nil
 }
 
+func addTemplateFlags(cmd *cobra.Command, tf *templateFlags) {
+	//nolint:lll
+	cmd.Flags().StringVarP(&tf.template, "template", "t", tf.template,
+		`row template definition (-t {"name":"format"} or -t {"name":"format(type)"}) or -t {"name":"format(type):format"})`+"\n"+
+			`arrays are declared with a single element (-t {"name":["format"]} or -t {"name":[{"sub":"format"}]})`+"\n"+
+			`default values are declared after an equal sign (-t {"name":"format=value"}, value can be a literal, now(), sequence() or sequence(start))`+"\n"+
+			`possible formats : string, numeric, boolean, binary, datetime, time, timestamp, timestamp_ms, timestamp_us, timestamp_ns, decimal, duration, uuid, ipv4, ipv6, cidr, url, email, json, auto, hidden`+"\n"+
+			`date and datetime layouts are declared between brackets (-t {"name":"datetime[02/01/2006 15:04|%d/%m/%Y]"})`+"\n"+
+			`decimals accept a precision, a scale and a rounding mode (-t {"name":"decimal(18,2)[half_up]"}, rounding modes : half_even, half_up, down, up, floor, ceiling)`+"\n"+
+			`durations accept a notation (-t {"name":"duration[iso8601]"}, notations : go, iso8601, s, ms)`+"\n"+
+			`binaries accept an encoding (-t {"name":"binary[hex]"}, encodings : base64, base64raw, base64url, base64urlraw, base32, base32raw, hex)`+"\n"+
+			`binaries holding numbers accept a byte layout after the type (-t {"name":"binary(int64,be)"}, layouts : le, be, network, varint, minimal)`+"\n"+
+			`possible types : int, int64, int32, int16, int8, uint, uint64, uint32, uint16, uint8, float64, float32, bool, byte, rune, string, []byte, time.Time, time.Duration, json.Number, *big.Int, *big.Float, *big.Rat`)
+	cmd.Flags().StringVarP(&tf.filename, "filename", "f", tf.filename, "name of row template filename")
+	cmd.Flags().StringVar(&tf.extraColumns, "extra-columns", tf.extraColumns,
+		"policy for columns not defined in the template : keep, drop or error (default keep)")
+	cmd.Flags().StringVar(&tf.tz, "tz", tf.tz,
+		"timezone of date and datetime columns on input and output (e.g. UTC, Europe/Paris)")
+	cmd.Flags().StringVar(&tf.assumeTz, "assume-tz", tf.assumeTz,
+		"timezone of date and datetime inputs without zone information (default is --tz or UTC)")
+}
+
 func bindViper(rootCmd cobra.Command) error {
 	err := viper.BindPFlag("verbosity", rootCmd.PersistentFlags().Lookup("verbosity"))
 	if err != nil {
@@ -222,7 +250,19 @@ func run(cmd *cobra.Command, args []string) {
 		over.MDC().Set("line-number", i)
 		i++
 
-		if err != nil {
+		var verr *jsonline.ValidationError
+
+		switch {
+		case errors.As(err, &verr):
+			for _, violation := range verr.Violations {
+				log.Error().
+					Str("path", violation.Path).
+					Str("constraint", violation.Constraint).
+					Interface("value", violation.Value).
+					Err(violation.Err).
+					Msg("failed to validate JSON line")
+			}
+		case err != nil:
 			log.Error().Err(err).Msg("failed to process JSON line")
 		}
 
@@ -246,8 +286,11 @@ func run(cmd *cobra.Command, args []string) {
 
 func getTemplateFlags(cmd *cobra.Command) (*templateFlags, error) {
 	tf := &templateFlags{
-		template: "",
-		filename: "",
+		template:     "",
+		filename:     "",
+		extraColumns: "",
+		tz:           "",
+		assumeTz:     "",
 	}
 
 	var err error
@@ -262,6 +305,21 @@ func getTemplateFlags(cmd *cobra.Command) (*templateFlags, error) {
 		return nil, fmt.Errorf("%w", err)
 	}
 
+	tf.extraColumns, err = cmd.Flags().GetString("extra-columns")
+	if err != nil {
+		return nil, fmt.Errorf("%w", err)
+	}
+
+	tf.tz, err = cmd.Flags().GetString("tz")
+	if err != nil {
+		return nil, fmt.Errorf("%w", err)
+	}
+
+	tf.assumeTz, err = cmd.Flags().GetString("assume-tz")
+	if err != nil {
+		return nil, fmt.Errorf("%w", err)
+	}
+
 	logFlags(tf)
 
 	return tf, nil
@@ -271,7 +329,10 @@ func logFlags(tf *templateFlags) {
 	var js json.RawMessage
 
 	tmp := log.Debug().
-		Str("filename", tf.filename)
+		Str("filename", tf.filename).
+		Str("extra-columns", tf.extraColumns).
+		Str("tz", tf.tz).
+		Str("assume-tz", tf.assumeTz)
 
 	if json.Unmarshal([]byte(tf.template), &js) != nil {
 		tmp = tmp.Str("in", tf.template)
@@ -288,13 +349,13 @@ func createTemplate(cmd *cobra.Command) (jsonline.Template, jsonline.Template, e
 		return nil, nil, err
 	}
 
-	ti, to, err := ParseRowDefinition(tf.filename)
+	ti, to, err := ParseRowDefinition(tf)
 	if err != nil {
 		return nil, nil, err
 	}
 
 	if len(tf.template) > 0 && tf.template != "{}" {
-		ti, to, err = createTemplateFromString(tf.template)
+		ti, to, err = createTemplateFromString(tf)
 		if err != nil {
 			return nil, nil, err
 		}
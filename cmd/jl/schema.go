@@ -0,0 +1,142 @@
+// Copyright (C) 2022 CGI France
+//
+// This file is part of JL.
+//
+// JL is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// JL is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with JL.  If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/cgi-fr/jsonline/pkg/jsonline"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+func newSchemaCommand() *cobra.Command {
+	cmd := &cobra.Command{ //nolint:exhaustivestruct
+		Use:   "schema",
+		Short: "Convert row definitions to and from JSON Schema",
+		Args:  cobra.NoArgs,
+	}
+
+	cmd.AddCommand(newSchemaExportCommand())
+	cmd.AddCommand(newSchemaImportCommand())
+
+	return cmd
+}
+
+func newSchemaExportCommand() *cobra.Command {
+	tf := templateFlags{
+		template:     "{}",
+		filename:     "./row.yml",
+		extraColumns: "",
+		tz:           "",
+		assumeTz:     "",
+	}
+
+	cmd := &cobra.Command{ //nolint:exhaustivestruct
+		Use:   "export",
+		Short: "Print the JSON Schema (draft 2020-12) of the input row template",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := exportSchema(cmd); err != nil {
+				log.Error().Err(err).Msg("failed to export JSON Schema")
+				os.Exit(1)
+			}
+		},
+		Example: fmt.Sprintf(`  %s schema export -f row.yml >schema.json`, name),
+	}
+
+	addTemplateFlags(cmd, &tf)
+
+	return cmd
+}
+
+func newSchemaImportCommand() *cobra.Command {
+	cmd := &cobra.Command{ //nolint:exhaustivestruct
+		Use:   "import [schema.json]",
+		Short: "Print the row definition (row.yml) of a JSON Schema read from a file or standard input",
+		Args:  cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := importSchema(args); err != nil {
+				log.Error().Err(err).Msg("failed to import JSON Schema")
+				os.Exit(1)
+			}
+		},
+		Example: fmt.Sprintf(`  %s schema import schema.json >row.yml`, name),
+	}
+
+	return cmd
+}
+
+func exportSchema(cmd *cobra.Command) error {
+	ti, _, err := createTemplate(cmd)
+	if err != nil {
+		return err
+	}
+
+	result, err := json.MarshalIndent(jsonline.NewJSONSchema(ti), "", "  ")
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+
+	_, err = fmt.Fprintln(os.Stdout, string(result))
+
+	return err
+}
+
+func importSchema(args []string) error {
+	var (
+		data []byte
+		err  error
+	)
+
+	if len(args) > 0 {
+		data, err = ioutil.ReadFile(args[0])
+	} else {
+		data, err = io.ReadAll(os.Stdin)
+	}
+
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+
+	schema := &jsonline.JSONSchema{} //nolint:exhaustivestruct
+	if err := json.Unmarshal(data, schema); err != nil {
+		return fmt.Errorf("%w", err)
+	}
+
+	template, err := schema.Template()
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+
+	def := jsonline.NewRowDefinition(template)
+
+	encoder := yaml.NewEncoder(os.Stdout)
+	encoder.SetIndent(2) //nolint:gomnd
+
+	if err := encoder.Encode(def); err != nil {
+		return fmt.Errorf("%w", err)
+	}
+
+	return encoder.Close()
+}
@@ -0,0 +1,154 @@
+// Copyright (C) 2022 CGI France
+//
+// This file is part of the jsonline library.
+//
+// The jsonline library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The jsonline library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with the jsonline library.  If not, see <http://www.gnu.org/licenses/>.
+//
+// Linking this library statically or dynamically with other modules is
+// making a combined work based on this library.  Thus, the terms and
+// conditions of the GNU General Public License cover the whole
+// combination.
+//
+// As a special exception, the copyright holders of this library give you
+// permission to link this library with independent modules to produce an
+// executable, regardless of the license terms of these independent
+// modules, and to copy and distribute the resulting executable under
+// terms of your choice, provided that you also meet, for each linked
+// independent module, the terms and conditions of the license of that
+// module.  An independent module is a module which is not derived from
+// or based on this library.  If you modify this library, you may extend
+// this exception to your version of the library, but you are not
+// obligated to do so.  If you do not wish to do so, delete this
+// exception statement from your version.
+
+package cast
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// ToBigRat converts the value to an exact rational number, floats are converted from their shortest decimal
+// representation.
+//
+//nolint:cyclop
+func ToBigRat(i interface{}) (interface{}, error) {
+	switch val := i.(type) {
+	case nil, *big.Rat:
+		return val, nil
+	case *big.Int:
+		return new(big.Rat).SetInt(val), nil
+	case *big.Float:
+		if val.IsInf() {
+			return nil, fmt.Errorf("%w: %v (%T)", ErrUnableToCastToBigRat, i, i)
+		}
+
+		r, _ := val.Rat(nil)
+
+		return r, nil
+	case float64:
+		return ToBigRat(strconv.FormatFloat(val, 'g', -1, 64))
+	case float32:
+		return ToBigRat(strconv.FormatFloat(float64(val), 'g', -1, 32))
+	case string:
+		r, ok := new(big.Rat).SetString(strings.TrimSpace(val))
+		if !ok {
+			return nil, fmt.Errorf("%w: %#v (%T)", ErrUnableToCastToBigRat, i, i)
+		}
+
+		return r, nil
+	case json.Number:
+		return ToBigRat(string(val))
+	case []byte:
+		return ToBigRat(string(val))
+	case bool:
+		return nil, fmt.Errorf("%w: %#v (%T)", ErrUnableToCastToBigRat, i, i)
+	default:
+		nb, err := ToNumber(val)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %#v (%T)", ErrUnableToCastToBigRat, i, i)
+		}
+
+		return ToBigRat(nb)
+	}
+}
+
+// ToBigInt converts the value to an arbitrary-precision integer, values with a fractional part can't be converted.
+func ToBigInt(i interface{}) (interface{}, error) {
+	if val, ok := i.(*big.Int); ok {
+		return val, nil
+	}
+
+	r, err := ToBigRat(i)
+	if err != nil || r == nil {
+		return nil, fmt.Errorf("%w: %#v (%T)", ErrUnableToCastToBigInt, i, i)
+	}
+
+	if !r.(*big.Rat).IsInt() {
+		return nil, fmt.Errorf("%w: %#v (%T) has a fractional part", ErrUnableToCastToBigInt, i, i)
+	}
+
+	return new(big.Int).Set(r.(*big.Rat).Num()), nil
+}
+
+// ToBigFloat converts the value to an arbitrary-precision float.
+func ToBigFloat(i interface{}) (interface{}, error) {
+	if val, ok := i.(*big.Float); ok {
+		return val, nil
+	}
+
+	r, err := ToBigRat(i)
+	if err != nil || r == nil {
+		return nil, fmt.Errorf("%w: %#v (%T)", ErrUnableToCastToBigFloat, i, i)
+	}
+
+	return new(big.Float).SetRat(r.(*big.Rat)), nil
+}
+
+// ratString returns the decimal representation of the rational number, exact if the decimal expansion is finite, or
+// rounded to 34 decimals otherwise.
+func ratString(r *big.Rat) string {
+	if r.IsInt() {
+		return r.Num().String()
+	}
+
+	// the expansion is finite if the denominator has no prime factors other than 2 and 5
+	denom := new(big.Int).Set(r.Denom())
+	two, five := big.NewInt(2), big.NewInt(5)
+	twos, fives := 0, 0
+	mod := new(big.Int)
+
+	for mod.Mod(denom, two).Sign() == 0 {
+		denom.Quo(denom, two)
+		twos++
+	}
+
+	for mod.Mod(denom, five).Sign() == 0 {
+		denom.Quo(denom, five)
+		fives++
+	}
+
+	if denom.Cmp(big.NewInt(1)) != 0 {
+		return r.FloatString(34)
+	}
+
+	if twos > fives {
+		return r.FloatString(twos)
+	}
+
+	return r.FloatString(fives)
+}
@@ -0,0 +1,89 @@
+// Copyright (C) 2022 CGI France
+//
+// This file is part of the jsonline library.
+//
+// The jsonline library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The jsonline library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with the jsonline library.  If not, see <http://www.gnu.org/licenses/>.
+
+package cast_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/cgi-fr/jsonline/pkg/cast"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCastToBigRat(t *testing.T) {
+	testdatas := []struct {
+		value    interface{}
+		expected interface{}
+		err      error
+	}{
+		{nil, nil, nil},
+		{int64(42), big.NewRat(42, 1), nil},
+		{float64(0.1), big.NewRat(1, 10), nil},
+		{json.Number("12345678901234567.891"), bigRat("12345678901234567891/1000"), nil},
+		{"3/4", big.NewRat(3, 4), nil},
+		{big.NewInt(7), big.NewRat(7, 1), nil},
+		{big.NewFloat(2.5), big.NewRat(5, 2), nil},
+		{"hello", nil, cast.ErrUnableToCastToBigRat},
+		{true, nil, cast.ErrUnableToCastToBigRat},
+	}
+
+	for _, td := range testdatas {
+		t.Run(fmt.Sprintf("%T(%v)", td.value, td.value), func(t *testing.T) {
+			result, err := cast.ToBigRat(td.value)
+			assert.ErrorIs(t, err, td.err)
+			assert.Equal(t, td.expected, result)
+		})
+	}
+}
+
+func TestCastBigToNumber(t *testing.T) {
+	testdatas := []struct {
+		value    interface{}
+		expected interface{}
+	}{
+		{new(big.Int).Exp(big.NewInt(10), big.NewInt(20), nil), json.Number("100000000000000000000")},
+		{big.NewFloat(2.5), json.Number("2.5")},
+		{big.NewRat(1, 8), json.Number("0.125")},
+		{big.NewRat(1, 3), json.Number("0.3333333333333333333333333333333333")},
+		{bigRat("12345678901234567891/1000"), json.Number("12345678901234567.891")},
+	}
+
+	for _, td := range testdatas {
+		t.Run(fmt.Sprintf("%T(%v)", td.value, td.value), func(t *testing.T) {
+			result, err := cast.ToNumber(td.value)
+			assert.NoError(t, err)
+			assert.Equal(t, td.expected, result)
+		})
+	}
+
+	i, err := cast.ToBigInt(json.Number("1.5"))
+	assert.ErrorIs(t, err, cast.ErrUnableToCastToBigInt)
+	assert.Nil(t, i)
+
+	i, err = cast.To((*big.Int)(nil), "123456789012345678901234567890")
+	assert.NoError(t, err)
+	assert.Equal(t, "123456789012345678901234567890", i.(*big.Int).String())
+}
+
+func bigRat(s string) *big.Rat {
+	r, _ := new(big.Rat).SetString(s)
+
+	return r
+}
@@ -283,3 +283,193 @@ func boolFromBytes(bytes []byte) (interface{}, error) {
 
 	return bytes[0] != 0, nil
 }
+
+// ByteLayout is the layout of numbers converted to and from bytes.
+type ByteLayout int8
+
+const (
+	LittleEndian     ByteLayout = iota // Fixed length, least significant byte first (default).
+	BigEndian                          // Fixed length, most significant byte first (network byte order).
+	Varint                             // Variable length varint, zigzag encoded for signed integers (integers only).
+	MinimalBigEndian                   // Shortest big-endian representation, two's complement for signed integers.
+)
+
+// ToBinaryLayout converts the value to bytes with the layout, Varint and MinimalBigEndian layouts apply to integers
+// only.
+//
+//nolint:cyclop
+func ToBinaryLayout(i interface{}, layout ByteLayout) (interface{}, error) {
+	if layout == LittleEndian {
+		return ToBinary(i)
+	}
+
+	if size, signed, bits, ok := integerBits(i); ok {
+		return encodeInteger(bits, size, signed, layout), nil
+	}
+
+	switch val := i.(type) {
+	case float64:
+		if layout != BigEndian {
+			return nil, fmt.Errorf("%w: %T(%v) can't use a variable length layout", ErrUnableToCastToBinary, i, i)
+		}
+
+		return encodeInteger(math.Float64bits(val), sizeOfFloat64, false, BigEndian), nil
+	case float32:
+		if layout != BigEndian {
+			return nil, fmt.Errorf("%w: %T(%v) can't use a variable length layout", ErrUnableToCastToBinary, i, i)
+		}
+
+		return encodeInteger(uint64(math.Float32bits(val)), sizeOfFloat32, false, BigEndian), nil
+	default:
+		return ToBinary(i)
+	}
+}
+
+// FromBinaryLayout converts the bytes to the target type with the layout, Varint and MinimalBigEndian layouts apply to
+// integers only.
+//
+//nolint:cyclop
+func FromBinaryLayout(targetType interface{}, bytes []byte, layout ByteLayout) (interface{}, error) {
+	if layout == LittleEndian {
+		return To(targetType, bytes)
+	}
+
+	if size, signed, _, ok := integerBits(targetType); ok {
+		val, err := decodeInteger(bytes, size, signed, layout)
+		if err != nil {
+			return nil, fmt.Errorf("%w to %T: %v", ErrUnableToCast, targetType, err)
+		}
+
+		return To(targetType, val)
+	}
+
+	switch targetType.(type) {
+	case float64:
+		if layout != BigEndian || len(bytes) != sizeOfFloat64 {
+			return nil, fmt.Errorf("%w: %T(%v)", ErrUnableToCastToFloat64, bytes, bytes)
+		}
+
+		return math.Float64frombits(binary.BigEndian.Uint64(bytes)), nil
+	case float32:
+		if layout != BigEndian || len(bytes) != sizeOfFloat32 {
+			return nil, fmt.Errorf("%w: %T(%v)", ErrUnableToCastToFloat32, bytes, bytes)
+		}
+
+		return math.Float32frombits(binary.BigEndian.Uint32(bytes)), nil
+	default:
+		return To(targetType, bytes)
+	}
+}
+
+// integerBits returns the size, the signedness and the bits of an integer (sign extended to 64 bits).
+//
+//nolint:cyclop
+func integerBits(i interface{}) (int, bool, uint64, bool) {
+	switch val := i.(type) {
+	case int:
+		return sizeOfInt, true, uint64(val), true
+	case int64:
+		return sizeOfInt64, true, uint64(val), true
+	case int32:
+		return sizeOfInt32, true, uint64(val), true
+	case int16:
+		return sizeOfInt16, true, uint64(val), true
+	case int8:
+		return sizeOfInt8, true, uint64(val), true
+	case uint:
+		return sizeOfUint, false, uint64(val), true
+	case uint64:
+		return sizeOfUint64, false, val, true
+	case uint32:
+		return sizeOfUint32, false, uint64(val), true
+	case uint16:
+		return sizeOfUint16, false, uint64(val), true
+	case uint8:
+		return sizeOfUint8, false, uint64(val), true
+	default:
+		return 0, false, 0, false
+	}
+}
+
+func encodeInteger(bits uint64, size int, signed bool, layout ByteLayout) []byte {
+	buffer := make([]byte, binary.MaxVarintLen64)
+
+	switch {
+	case layout == Varint && signed:
+		return buffer[:binary.PutVarint(buffer, int64(bits))]
+	case layout == Varint:
+		return buffer[:binary.PutUvarint(buffer, bits)]
+	case layout == MinimalBigEndian:
+		size = minimalSize(bits, signed)
+	}
+
+	binary.BigEndian.PutUint64(buffer, bits)
+
+	return buffer[sizeOfUint64-size : sizeOfUint64]
+}
+
+// minimalSize returns the smallest number of bytes holding the integer (at least one).
+func minimalSize(bits uint64, signed bool) int {
+	size := 1
+
+	for ; size < sizeOfUint64; size++ {
+		if signed {
+			limit := int64(1) << (8*size - 1)
+			if v := int64(bits); v >= -limit && v < limit {
+				break
+			}
+		} else if bits < uint64(1)<<(8*size) {
+			break
+		}
+	}
+
+	return size
+}
+
+// decodeInteger returns the int64 (signed) or uint64 (unsigned) integer held by the bytes.
+//
+//nolint:cyclop
+func decodeInteger(bytes []byte, size int, signed bool, layout ByteLayout) (interface{}, error) {
+	switch layout {
+	case Varint:
+		var (
+			val interface{}
+			n   int
+		)
+
+		if signed {
+			val, n = binary.Varint(bytes)
+		} else {
+			val, n = binary.Uvarint(bytes)
+		}
+
+		if n != len(bytes) || n == 0 {
+			return nil, fmt.Errorf("%w: invalid varint %v", ErrUnableToCastToBinary, bytes)
+		}
+
+		return val, nil
+	case BigEndian:
+		if len(bytes) != size {
+			return nil, fmt.Errorf("%w: %v has not %d bytes", ErrUnableToCastToBinary, bytes, size)
+		}
+	case MinimalBigEndian:
+		if len(bytes) == 0 || len(bytes) > sizeOfUint64 {
+			return nil, fmt.Errorf("%w: %v has not 1 to %d bytes", ErrUnableToCastToBinary, bytes, sizeOfUint64)
+		}
+	case LittleEndian:
+	}
+
+	padded := make([]byte, sizeOfUint64)
+	copy(padded[sizeOfUint64-len(bytes):], bytes)
+
+	bits := binary.BigEndian.Uint64(padded)
+
+	if !signed {
+		return bits, nil
+	}
+
+	// sign extension
+	shift := uint(8 * (sizeOfUint64 - len(bytes)))
+
+	return int64(bits<<shift) >> shift, nil
+}
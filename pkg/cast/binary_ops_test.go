@@ -120,3 +120,46 @@ func TestUnsafeUint16(t *testing.T) {
 		})
 	}
 }
+
+func TestByteLayouts(t *testing.T) {
+	testdatas := []struct {
+		value  interface{}
+		layout ByteLayout
+		bytes  []byte
+	}{
+		{int32(1), BigEndian, []byte{0, 0, 0, 1}},
+		{int64(256), BigEndian, []byte{0, 0, 0, 0, 0, 0, 1, 0}},
+		{uint16(0xabcd), BigEndian, []byte{0xab, 0xcd}},
+		{float64(1), BigEndian, []byte{0x3f, 0xf0, 0, 0, 0, 0, 0, 0}},
+		{int64(-300), Varint, []byte{0xd7, 0x04}},
+		{uint64(300), Varint, []byte{0xac, 0x02}},
+		{int64(300), MinimalBigEndian, []byte{0x01, 0x2c}},
+		{int64(-1), MinimalBigEndian, []byte{0xff}},
+		{uint32(0), MinimalBigEndian, []byte{0x00}},
+	}
+	for _, td := range testdatas {
+		t.Run(fmt.Sprintf("%T(%v)", td.value, td.value), func(t *testing.T) {
+			bytes, err := ToBinaryLayout(td.value, td.layout)
+			assert.NoError(t, err)
+			assert.Equal(t, td.bytes, bytes)
+
+			result, err := FromBinaryLayout(td.value, bytes.([]byte), td.layout)
+			assert.NoError(t, err)
+			assert.Equal(t, td.value, result)
+		})
+	}
+}
+
+func TestByteLayoutsErrors(t *testing.T) {
+	_, err := FromBinaryLayout(int32(0), []byte{0, 1}, BigEndian)
+	assert.Error(t, err)
+
+	_, err = FromBinaryLayout(int8(0), []byte{0x01, 0x00}, MinimalBigEndian)
+	assert.Error(t, err)
+
+	_, err = FromBinaryLayout(int64(0), []byte{0xac}, Varint)
+	assert.Error(t, err)
+
+	_, err = ToBinaryLayout(float32(1), Varint)
+	assert.Error(t, err)
+}
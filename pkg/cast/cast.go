@@ -38,6 +38,11 @@
 import (
 	"encoding/json"
 	"fmt"
+	"math/big"
+	"net"
+	"net/mail"
+	"net/netip"
+	"net/url"
 	"time"
 )
 
@@ -77,9 +82,35 @@ func To(targetType interface{}, val interface{}) (interface{}, error) {
 		return ToBinary(val)
 	case time.Time:
 		return ToTime(val)
+	case time.Duration:
+		return ToDuration(val)
 	case json.Number:
 		return ToNumber(val)
+	case *big.Int:
+		return ToBigInt(val)
+	case *big.Float:
+		return ToBigFloat(val)
+	case *big.Rat:
+		return ToBigRat(val)
+	case [16]byte:
+		return ToUUID(val)
+	case netip.Addr:
+		return ToAddr(val)
+	case net.IP:
+		return ToIP(val)
+	case netip.Prefix:
+		return ToPrefix(val)
+	case *net.IPNet:
+		return ToIPNet(val)
+	case *url.URL:
+		return ToURL(val)
+	case *mail.Address:
+		return ToMailAddress(val)
 	default:
+		if result, ok, err := toCustom(targetType, val); ok {
+			return result, err
+		}
+
 		return nil, fmt.Errorf("%w: %#v to %T", ErrUnableToCast, val, targetType)
 	}
 }
@@ -0,0 +1,156 @@
+// Copyright (C) 2022 CGI France
+//
+// This file is part of the jsonline library.
+//
+// The jsonline library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The jsonline library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with the jsonline library.  If not, see <http://www.gnu.org/licenses/>.
+//
+// Linking this library statically or dynamically with other modules is
+// making a combined work based on this library.  Thus, the terms and
+// conditions of the GNU General Public License cover the whole
+// combination.
+//
+// As a special exception, the copyright holders of this library give you
+// permission to link this library with independent modules to produce an
+// executable, regardless of the license terms of these independent
+// modules, and to copy and distribute the resulting executable under
+// terms of your choice, provided that you also meet, for each linked
+// independent module, the terms and conditions of the license of that
+// module.  An independent module is a module which is not derived from
+// or based on this library.  If you modify this library, you may extend
+// this exception to your version of the library, but you are not
+// obligated to do so.  If you do not wish to do so, delete this
+// exception statement from your version.
+
+package cast
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//nolint:gochecknoglobals
+var iso8601Duration = regexp.MustCompile(
+	`^([-+])?P(?:([0-9.,]+)W)?(?:([0-9.,]+)D)?(?:T(?:([0-9.,]+)H)?(?:([0-9.,]+)M)?(?:([0-9.,]+)S)?)?$`)
+
+// ToDuration converts the value to a duration, strings can be Go durations (e.g. : "1h30m"), ISO 8601 durations
+// (e.g. : "PT1H30M") or numbers of seconds.
+func ToDuration(i interface{}) (interface{}, error) {
+	return ToDurationUnit(i, time.Second)
+}
+
+// ToDurationUnit converts the value to a duration, numbers are the count of units (time.Second, time.Millisecond, ...)
+// and can be fractional.
+//
+//nolint:cyclop
+func ToDurationUnit(i interface{}, unit time.Duration) (interface{}, error) {
+	switch val := i.(type) {
+	case nil, time.Duration:
+		return val, nil
+	case string:
+		if d, err := time.ParseDuration(val); err == nil {
+			return d, nil
+		}
+
+		if d, ok := parseISO8601Duration(val); ok {
+			return d, nil
+		}
+
+		if f, err := strconv.ParseFloat(val, 64); err == nil {
+			return ToDurationUnit(f, unit)
+		}
+
+		return nil, fmt.Errorf("%w: %#v (%T)", ErrUnableToCastToDuration, i, i)
+	case []byte:
+		return ToDurationUnit(string(val), unit)
+	case json.Number:
+		return ToDurationUnit(string(val), unit)
+	case float64:
+		return time.Duration(math.Round(val * float64(unit))), nil
+	case float32:
+		return ToDurationUnit(float64(val), unit)
+	default:
+		i64, err := ToInt64(val)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %#v (%T)", ErrUnableToCastToDuration, i, i)
+		}
+
+		return time.Duration(i64.(int64)) * unit, nil
+	}
+}
+
+// parseISO8601Duration parses an ISO 8601 duration, years and months are not supported as their length varies.
+func parseISO8601Duration(str string) (time.Duration, bool) {
+	submatches := iso8601Duration.FindStringSubmatch(str)
+	if submatches == nil || str == "P" || strings.HasSuffix(str, "T") {
+		return 0, false
+	}
+
+	units := []time.Duration{7 * 24 * time.Hour, 24 * time.Hour, time.Hour, time.Minute, time.Second}
+	result := time.Duration(0)
+
+	for n, unit := range units {
+		if len(submatches[n+2]) == 0 {
+			continue
+		}
+
+		f, err := strconv.ParseFloat(strings.ReplaceAll(submatches[n+2], ",", "."), 64)
+		if err != nil {
+			return 0, false
+		}
+
+		result += time.Duration(math.Round(f * float64(unit)))
+	}
+
+	if submatches[1] == "-" {
+		result = -result
+	}
+
+	return result, true
+}
+
+// ISO8601Duration returns the ISO 8601 representation of the duration, with hours, minutes and seconds only
+// (e.g. : "PT36H30M", "PT0.5S").
+func ISO8601Duration(d time.Duration) string {
+	if d == 0 {
+		return "PT0S"
+	}
+
+	sign := ""
+	if d < 0 {
+		sign, d = "-", -d
+	}
+
+	result := sign + "PT"
+
+	if hours := d / time.Hour; hours > 0 {
+		result += strconv.FormatInt(int64(hours), 10) + "H"
+		d -= hours * time.Hour
+	}
+
+	if minutes := d / time.Minute; minutes > 0 {
+		result += strconv.FormatInt(int64(minutes), 10) + "M"
+		d -= minutes * time.Minute
+	}
+
+	if d > 0 {
+		result += strconv.FormatFloat(d.Seconds(), 'f', -1, 64) + "S"
+	}
+
+	return result
+}
@@ -0,0 +1,68 @@
+// Copyright (C) 2022 CGI France
+//
+// This file is part of the jsonline library.
+//
+// The jsonline library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The jsonline library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with the jsonline library.  If not, see <http://www.gnu.org/licenses/>.
+
+package cast_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/cgi-fr/jsonline/pkg/cast"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCastToDuration(t *testing.T) {
+	testdatas := []struct {
+		value    interface{}
+		expected interface{}
+		err      error
+	}{
+		{nil, nil, nil},
+		{"1h30m", 90 * time.Minute, nil},
+		{"PT1H30M", 90 * time.Minute, nil},
+		{"P1W2DT0.5S", 9*24*time.Hour + 500*time.Millisecond, nil},
+		{"-PT1,5S", -1500 * time.Millisecond, nil},
+		{"5400", 90 * time.Minute, nil},
+		{json.Number("1.5"), 1500 * time.Millisecond, nil},
+		{int64(60), time.Minute, nil},
+		{float64(0.25), 250 * time.Millisecond, nil},
+		{"P1Y", nil, cast.ErrUnableToCastToDuration},
+		{"PT", nil, cast.ErrUnableToCastToDuration},
+		{"hello", nil, cast.ErrUnableToCastToDuration},
+	}
+
+	for _, td := range testdatas {
+		t.Run(fmt.Sprintf("%T(%v)", td.value, td.value), func(t *testing.T) {
+			result, err := cast.ToDuration(td.value)
+			assert.ErrorIs(t, err, td.err)
+			assert.Equal(t, td.expected, result)
+		})
+	}
+
+	d, err := cast.ToDurationUnit(json.Number("1500"), time.Millisecond)
+	assert.NoError(t, err)
+	assert.Equal(t, 1500*time.Millisecond, d)
+}
+
+func TestISO8601Duration(t *testing.T) {
+	assert.Equal(t, "PT0S", cast.ISO8601Duration(0))
+	assert.Equal(t, "PT36H30M", cast.ISO8601Duration(36*time.Hour+30*time.Minute))
+	assert.Equal(t, "PT1M0.5S", cast.ISO8601Duration(time.Minute+500*time.Millisecond))
+	assert.Equal(t, "-PT1H", cast.ISO8601Duration(-time.Hour))
+}
@@ -40,23 +40,32 @@
 )
 
 var (
-	ErrUnableToCast          = errors.New("unable to cast value")
-	ErrUnableToCastToInt     = fmt.Errorf("%w to int", ErrUnableToCast)
-	ErrUnableToCastToInt64   = fmt.Errorf("%w to int64", ErrUnableToCast)
-	ErrUnableToCastToInt32   = fmt.Errorf("%w to int32", ErrUnableToCast)
-	ErrUnableToCastToInt16   = fmt.Errorf("%w to int16", ErrUnableToCast)
-	ErrUnableToCastToInt8    = fmt.Errorf("%w to int8", ErrUnableToCast)
-	ErrUnableToCastToUint    = fmt.Errorf("%w to uint", ErrUnableToCast)
-	ErrUnableToCastToUint64  = fmt.Errorf("%w to uint64", ErrUnableToCast)
-	ErrUnableToCastToUint32  = fmt.Errorf("%w to uint32", ErrUnableToCast)
-	ErrUnableToCastToUint16  = fmt.Errorf("%w to uint16", ErrUnableToCast)
-	ErrUnableToCastToUint8   = fmt.Errorf("%w to uint8", ErrUnableToCast)
-	ErrUnableToCastToFloat64 = fmt.Errorf("%w to float64", ErrUnableToCast)
-	ErrUnableToCastToFloat32 = fmt.Errorf("%w to float32", ErrUnableToCast)
-	ErrUnableToCastToBool    = fmt.Errorf("%w to bool", ErrUnableToCast)
-	ErrUnableToCastToNumber  = fmt.Errorf("%w to number", ErrUnableToCast)
-	ErrUnableToCastToString  = fmt.Errorf("%w to string", ErrUnableToCast)
-	ErrUnableToCastToBinary  = fmt.Errorf("%w to binary", ErrUnableToCast)
-	ErrUnableToCastToTime    = fmt.Errorf("%w to time", ErrUnableToCast)
-	ErrUnableToCastToDate    = fmt.Errorf("%w to date", ErrUnableToCast)
+	ErrUnableToCast              = errors.New("unable to cast value")
+	ErrUnableToCastToInt         = fmt.Errorf("%w to int", ErrUnableToCast)
+	ErrUnableToCastToInt64       = fmt.Errorf("%w to int64", ErrUnableToCast)
+	ErrUnableToCastToInt32       = fmt.Errorf("%w to int32", ErrUnableToCast)
+	ErrUnableToCastToInt16       = fmt.Errorf("%w to int16", ErrUnableToCast)
+	ErrUnableToCastToInt8        = fmt.Errorf("%w to int8", ErrUnableToCast)
+	ErrUnableToCastToUint        = fmt.Errorf("%w to uint", ErrUnableToCast)
+	ErrUnableToCastToUint64      = fmt.Errorf("%w to uint64", ErrUnableToCast)
+	ErrUnableToCastToUint32      = fmt.Errorf("%w to uint32", ErrUnableToCast)
+	ErrUnableToCastToUint16      = fmt.Errorf("%w to uint16", ErrUnableToCast)
+	ErrUnableToCastToUint8       = fmt.Errorf("%w to uint8", ErrUnableToCast)
+	ErrUnableToCastToFloat64     = fmt.Errorf("%w to float64", ErrUnableToCast)
+	ErrUnableToCastToFloat32     = fmt.Errorf("%w to float32", ErrUnableToCast)
+	ErrUnableToCastToBool        = fmt.Errorf("%w to bool", ErrUnableToCast)
+	ErrUnableToCastToNumber      = fmt.Errorf("%w to number", ErrUnableToCast)
+	ErrUnableToCastToString      = fmt.Errorf("%w to string", ErrUnableToCast)
+	ErrUnableToCastToBinary      = fmt.Errorf("%w to binary", ErrUnableToCast)
+	ErrUnableToCastToTime        = fmt.Errorf("%w to time", ErrUnableToCast)
+	ErrUnableToCastToDate        = fmt.Errorf("%w to date", ErrUnableToCast)
+	ErrUnableToCastToBigInt      = fmt.Errorf("%w to big.Int", ErrUnableToCast)
+	ErrUnableToCastToBigFloat    = fmt.Errorf("%w to big.Float", ErrUnableToCast)
+	ErrUnableToCastToBigRat      = fmt.Errorf("%w to big.Rat", ErrUnableToCast)
+	ErrUnableToCastToDuration    = fmt.Errorf("%w to duration", ErrUnableToCast)
+	ErrUnableToCastToUUID        = fmt.Errorf("%w to uuid", ErrUnableToCast)
+	ErrUnableToCastToIP          = fmt.Errorf("%w to ip address", ErrUnableToCast)
+	ErrUnableToCastToPrefix      = fmt.Errorf("%w to ip prefix", ErrUnableToCast)
+	ErrUnableToCastToURL         = fmt.Errorf("%w to url", ErrUnableToCast)
+	ErrUnableToCastToMailAddress = fmt.Errorf("%w to email address", ErrUnableToCast)
 )
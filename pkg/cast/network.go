@@ -0,0 +1,238 @@
+// Copyright (C) 2022 CGI France
+//
+// This file is part of the jsonline library.
+//
+// The jsonline library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The jsonline library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with the jsonline library.  If not, see <http://www.gnu.org/licenses/>.
+//
+// Linking this library statically or dynamically with other modules is
+// making a combined work based on this library.  Thus, the terms and
+// conditions of the GNU General Public License cover the whole
+// combination.
+//
+// As a special exception, the copyright holders of this library give you
+// permission to link this library with independent modules to produce an
+// executable, regardless of the license terms of these independent
+// modules, and to copy and distribute the resulting executable under
+// terms of your choice, provided that you also meet, for each linked
+// independent module, the terms and conditions of the license of that
+// module.  An independent module is a module which is not derived from
+// or based on this library.  If you modify this library, you may extend
+// this exception to your version of the library, but you are not
+// obligated to do so.  If you do not wish to do so, delete this
+// exception statement from your version.
+
+package cast
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/netip"
+	"net/url"
+	"strings"
+)
+
+const sizeOfUUID = 16
+
+// ToUUID converts the value to a UUID, strings can be hyphenated (e.g. : "6ba7b810-9dad-11d1-80b4-00c04fd430c8"),
+// plain hexadecimal, between braces or prefixed by "urn:uuid:", in any case.
+func ToUUID(i interface{}) (interface{}, error) {
+	switch val := i.(type) {
+	case nil, [sizeOfUUID]byte:
+		return val, nil
+	case string:
+		str := strings.TrimPrefix(strings.ToLower(val), "urn:uuid:")
+		if strings.HasPrefix(str, "{") && strings.HasSuffix(str, "}") {
+			str = str[1 : len(str)-1]
+		}
+
+		if len(str) == 36 && str[8] == '-' && str[13] == '-' && str[18] == '-' && str[23] == '-' {
+			str = strings.ReplaceAll(str, "-", "")
+		}
+
+		var result [sizeOfUUID]byte
+		if n, err := hex.Decode(result[:], []byte(str)); err != nil || n != sizeOfUUID || len(str) != 2*sizeOfUUID {
+			return nil, fmt.Errorf("%w: %#v (%T)", ErrUnableToCastToUUID, i, i)
+		}
+
+		return result, nil
+	case []byte:
+		if len(val) == sizeOfUUID {
+			var result [sizeOfUUID]byte
+
+			copy(result[:], val)
+
+			return result, nil
+		}
+
+		return ToUUID(string(val))
+	default:
+		str, err := ToString(val)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %#v (%T)", ErrUnableToCastToUUID, i, i)
+		}
+
+		return ToUUID(str)
+	}
+}
+
+// UUIDString returns the canonical representation of a UUID (lowercase and hyphenated).
+func UUIDString(uuid [16]byte) string {
+	str := hex.EncodeToString(uuid[:])
+
+	return str[0:8] + "-" + str[8:12] + "-" + str[12:16] + "-" + str[16:20] + "-" + str[20:32]
+}
+
+// ToAddr converts the value to an IP address (IPv4 or IPv6, with an optional zone).
+func ToAddr(i interface{}) (interface{}, error) {
+	switch val := i.(type) {
+	case nil, netip.Addr:
+		return val, nil
+	case net.IP:
+		addr, ok := netip.AddrFromSlice(val)
+		if !ok {
+			return nil, fmt.Errorf("%w: %#v (%T)", ErrUnableToCastToIP, i, i)
+		}
+
+		if val.To4() != nil {
+			addr = addr.Unmap()
+		}
+
+		return addr, nil
+	case string:
+		addr, err := netip.ParseAddr(val)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %#v (%T)", ErrUnableToCastToIP, i, i)
+		}
+
+		return addr, nil
+	default:
+		str, err := ToString(val)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %#v (%T)", ErrUnableToCastToIP, i, i)
+		}
+
+		return ToAddr(str)
+	}
+}
+
+// ToIP converts the value to a net.IP, zones are not supported.
+func ToIP(i interface{}) (interface{}, error) {
+	switch val := i.(type) {
+	case nil, net.IP:
+		return val, nil
+	default:
+		addr, err := ToAddr(val)
+		if err != nil || addr.(netip.Addr).Zone() != "" {
+			return nil, fmt.Errorf("%w: %#v (%T)", ErrUnableToCastToIP, i, i)
+		}
+
+		return net.IP(addr.(netip.Addr).AsSlice()), nil
+	}
+}
+
+// ToPrefix converts the value to an IP prefix in CIDR notation (e.g. : "192.168.0.0/16").
+func ToPrefix(i interface{}) (interface{}, error) {
+	switch val := i.(type) {
+	case nil, netip.Prefix:
+		return val, nil
+	case *net.IPNet:
+		return ToPrefix(val.String())
+	case string:
+		prefix, err := netip.ParsePrefix(val)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %#v (%T)", ErrUnableToCastToPrefix, i, i)
+		}
+
+		return prefix, nil
+	default:
+		str, err := ToString(val)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %#v (%T)", ErrUnableToCastToPrefix, i, i)
+		}
+
+		return ToPrefix(str)
+	}
+}
+
+// ToIPNet converts the value to a *net.IPNet, the address is masked by the prefix length.
+func ToIPNet(i interface{}) (interface{}, error) {
+	switch val := i.(type) {
+	case nil, *net.IPNet:
+		return val, nil
+	default:
+		prefix, err := ToPrefix(val)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %#v (%T)", ErrUnableToCastToPrefix, i, i)
+		}
+
+		_, ipnet, err := net.ParseCIDR(prefix.(netip.Prefix).String())
+		if err != nil {
+			return nil, fmt.Errorf("%w: %#v (%T)", ErrUnableToCastToPrefix, i, i)
+		}
+
+		return ipnet, nil
+	}
+}
+
+// ToURL converts the value to an absolute URL, the scheme and the host are lowercased.
+func ToURL(i interface{}) (interface{}, error) {
+	switch val := i.(type) {
+	case nil, *url.URL:
+		return val, nil
+	case string:
+		u, err := url.Parse(val)
+		if err != nil || !u.IsAbs() {
+			return nil, fmt.Errorf("%w: %#v (%T)", ErrUnableToCastToURL, i, i)
+		}
+
+		u.Host = strings.ToLower(u.Host)
+
+		return u, nil
+	default:
+		str, err := ToString(val)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %#v (%T)", ErrUnableToCastToURL, i, i)
+		}
+
+		return ToURL(str)
+	}
+}
+
+// ToMailAddress converts the value to an email address, strings must be a bare address (e.g. : "john@example.com")
+// and the domain is lowercased.
+func ToMailAddress(i interface{}) (interface{}, error) {
+	switch val := i.(type) {
+	case nil, *mail.Address:
+		return val, nil
+	case string:
+		addr, err := mail.ParseAddress(val)
+		if err != nil || addr.Name != "" || addr.Address != val {
+			return nil, fmt.Errorf("%w: %#v (%T)", ErrUnableToCastToMailAddress, i, i)
+		}
+
+		at := strings.LastIndex(addr.Address, "@")
+		addr.Address = addr.Address[:at] + strings.ToLower(addr.Address[at:])
+
+		return addr, nil
+	default:
+		str, err := ToString(val)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %#v (%T)", ErrUnableToCastToMailAddress, i, i)
+		}
+
+		return ToMailAddress(str)
+	}
+}
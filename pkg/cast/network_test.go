@@ -0,0 +1,114 @@
+// Copyright (C) 2022 CGI France
+//
+// This file is part of the jsonline library.
+//
+// The jsonline library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The jsonline library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with the jsonline library.  If not, see <http://www.gnu.org/licenses/>.
+
+package cast_test
+
+import (
+	"fmt"
+	"net"
+	"net/netip"
+	"testing"
+
+	"github.com/cgi-fr/jsonline/pkg/cast"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCastToUUID(t *testing.T) {
+	expected := [16]byte{
+		0x6b, 0xa7, 0xb8, 0x10, 0x9d, 0xad, 0x11, 0xd1, 0x80, 0xb4, 0x00, 0xc0, 0x4f, 0xd4, 0x30, 0xc8,
+	}
+
+	testdatas := []struct {
+		value    interface{}
+		expected interface{}
+		err      error
+	}{
+		{nil, nil, nil},
+		{"6ba7b810-9dad-11d1-80b4-00c04fd430c8", expected, nil},
+		{"6BA7B810-9DAD-11D1-80B4-00C04FD430C8", expected, nil},
+		{"{6ba7b810-9dad-11d1-80b4-00c04fd430c8}", expected, nil},
+		{"urn:uuid:6ba7b810-9dad-11d1-80b4-00c04fd430c8", expected, nil},
+		{"6ba7b8109dad11d180b400c04fd430c8", expected, nil},
+		{expected[:], expected, nil},
+		{"6ba7b810-9dad-11d1-80b4-00c04fd430", nil, cast.ErrUnableToCastToUUID},
+		{"6ba7b810-9dad-11d1-80b4-00c04fd430zz", nil, cast.ErrUnableToCastToUUID},
+		{true, nil, cast.ErrUnableToCastToUUID},
+	}
+
+	for _, td := range testdatas {
+		t.Run(fmt.Sprintf("%T(%v)", td.value, td.value), func(t *testing.T) {
+			result, err := cast.ToUUID(td.value)
+			assert.ErrorIs(t, err, td.err)
+			assert.Equal(t, td.expected, result)
+		})
+	}
+
+	assert.Equal(t, "6ba7b810-9dad-11d1-80b4-00c04fd430c8", cast.UUIDString(expected))
+}
+
+func TestCastToIP(t *testing.T) {
+	addr, err := cast.ToAddr("2001:0DB8::0001")
+	assert.NoError(t, err)
+	assert.Equal(t, netip.MustParseAddr("2001:db8::1"), addr)
+
+	addr, err = cast.ToAddr(net.IPv4(192, 168, 0, 1))
+	assert.NoError(t, err)
+	assert.Equal(t, netip.MustParseAddr("192.168.0.1"), addr)
+
+	ip, err := cast.ToIP("192.168.0.1")
+	assert.NoError(t, err)
+	assert.Equal(t, "192.168.0.1", ip.(net.IP).String())
+
+	_, err = cast.ToIP("fe80::1%eth0")
+	assert.ErrorIs(t, err, cast.ErrUnableToCastToIP)
+
+	_, err = cast.ToAddr("192.168.0.256")
+	assert.ErrorIs(t, err, cast.ErrUnableToCastToIP)
+
+	prefix, err := cast.ToPrefix("2001:db8:0::/32")
+	assert.NoError(t, err)
+	assert.Equal(t, netip.MustParsePrefix("2001:db8::/32"), prefix)
+
+	ipnet, err := cast.ToIPNet("10.1.2.3/8")
+	assert.NoError(t, err)
+	assert.Equal(t, "10.0.0.0/8", ipnet.(*net.IPNet).String())
+
+	_, err = cast.ToPrefix("10.0.0.0/33")
+	assert.ErrorIs(t, err, cast.ErrUnableToCastToPrefix)
+}
+
+func TestCastToURLAndMailAddress(t *testing.T) {
+	u, err := cast.ToURL("HTTPS://Example.COM/Path?q=1")
+	assert.NoError(t, err)
+	assert.Equal(t, "https://example.com/Path?q=1", u.(fmt.Stringer).String())
+
+	_, err = cast.ToURL("/relative/path")
+	assert.ErrorIs(t, err, cast.ErrUnableToCastToURL)
+
+	addr, err := cast.ToMailAddress("John.Doe@Example.COM")
+	assert.NoError(t, err)
+
+	str, err := cast.ToString(addr)
+	assert.NoError(t, err)
+	assert.Equal(t, "John.Doe@example.com", str)
+
+	_, err = cast.ToMailAddress("John Doe <john@example.com>")
+	assert.ErrorIs(t, err, cast.ErrUnableToCastToMailAddress)
+
+	_, err = cast.ToMailAddress("not an email")
+	assert.ErrorIs(t, err, cast.ErrUnableToCastToMailAddress)
+}
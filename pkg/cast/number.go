@@ -38,6 +38,7 @@
 import (
 	"encoding/json"
 	"fmt"
+	"math/big"
 	"strconv"
 	"time"
 )
@@ -82,7 +83,17 @@ func ToNumber(i interface{}) (interface{}, error) {
 		return json.Number(val), nil
 	case time.Time:
 		return ToNumber(val.Unix())
+	case *big.Int:
+		return json.Number(val.String()), nil
+	case *big.Float:
+		return json.Number(val.Text('f', -1)), nil
+	case *big.Rat:
+		return json.Number(ratString(val)), nil
 	default:
+		if u, ok := underlying(val); ok {
+			return ToNumber(u)
+		}
+
 		return nil, fmt.Errorf("%w: %#v (%T)", ErrUnableToCastToNumber, i, i)
 	}
 }
@@ -0,0 +1,182 @@
+// Copyright (C) 2022 CGI France
+//
+// This file is part of the jsonline library.
+//
+// The jsonline library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The jsonline library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with the jsonline library.  If not, see <http://www.gnu.org/licenses/>.
+//
+// Linking this library statically or dynamically with other modules is
+// making a combined work based on this library.  Thus, the terms and
+// conditions of the GNU General Public License cover the whole
+// combination.
+//
+// As a special exception, the copyright holders of this library give you
+// permission to link this library with independent modules to produce an
+// executable, regardless of the license terms of these independent
+// modules, and to copy and distribute the resulting executable under
+// terms of your choice, provided that you also meet, for each linked
+// independent module, the terms and conditions of the license of that
+// module.  An independent module is a module which is not derived from
+// or based on this library.  If you modify this library, you may extend
+// this exception to your version of the library, but you are not
+// obligated to do so.  If you do not wish to do so, delete this
+// exception statement from your version.
+
+package cast
+
+import (
+	"bytes"
+	"database/sql"
+	"database/sql/driver"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// Converter converts a value to a rawtype.
+type Converter func(i interface{}) (interface{}, error)
+
+//nolint:gochecknoglobals
+var converters = map[reflect.Type]Converter{}
+
+// Register declares the converter used by To for targets of the same type as target (e.g. : Money{}). Converters must
+// be registered before any conversion (e.g. : in an init function), the registry is not safe for concurrent use.
+func Register(target interface{}, fn Converter) {
+	converters[reflect.TypeOf(target)] = fn
+}
+
+// toCustom converts the value to a registered type, or to a type implementing encoding.TextUnmarshaler,
+// json.Unmarshaler or sql.Scanner (with a value or a pointer receiver). It returns false if the target type is not
+// supported.
+//
+//nolint:cyclop
+func toCustom(targetType interface{}, i interface{}) (interface{}, bool, error) {
+	typ := reflect.TypeOf(targetType)
+
+	if fn, ok := converters[typ]; ok {
+		result, err := fn(i)
+		if err != nil {
+			return nil, true, fmt.Errorf("%w: %#v to %T: %v", ErrUnableToCast, i, targetType, err)
+		}
+
+		return result, true, nil
+	}
+
+	// ptr is a pointer to a new value of the target type, or a new value if the target type is a pointer
+	var ptr, result reflect.Value
+
+	if typ.Kind() == reflect.Ptr {
+		ptr = reflect.New(typ.Elem())
+		result = ptr
+	} else {
+		ptr = reflect.New(typ)
+		result = ptr.Elem()
+	}
+
+	switch ptr.Interface().(type) {
+	case encoding.TextUnmarshaler, json.Unmarshaler, sql.Scanner:
+	default:
+		return nil, false, nil
+	}
+
+	if i == nil || reflect.TypeOf(i) == typ {
+		return i, true, nil
+	}
+
+	var err error
+
+	switch target := ptr.Interface().(type) {
+	case encoding.TextUnmarshaler:
+		var text []byte
+		if text, err = textOf(i); err == nil {
+			err = target.UnmarshalText(text)
+		}
+	case json.Unmarshaler:
+		var data []byte
+		if data, err = json.Marshal(i); err == nil {
+			err = target.UnmarshalJSON(data)
+		}
+	case sql.Scanner:
+		if valuer, ok := i.(driver.Valuer); ok {
+			i, err = valuer.Value()
+		}
+
+		if err == nil {
+			err = target.Scan(i)
+		}
+	}
+
+	if err != nil {
+		return nil, true, fmt.Errorf("%w: %#v to %T: %v", ErrUnableToCast, i, targetType, err)
+	}
+
+	return result.Interface(), true, nil
+}
+
+// textOf returns the text representation of the value.
+func textOf(i interface{}) ([]byte, error) {
+	if marshaler, ok := i.(encoding.TextMarshaler); ok {
+		text, err := marshaler.MarshalText()
+		if err != nil {
+			return nil, fmt.Errorf("%w", err)
+		}
+
+		return text, nil
+	}
+
+	str, err := ToString(i)
+	if err != nil {
+		return nil, err
+	}
+
+	return []byte(str.(string)), nil
+}
+
+// underlying returns the value represented by a type implementing driver.Valuer, encoding.TextMarshaler or
+// json.Marshaler, it returns false if the value implements none of them.
+func underlying(i interface{}) (interface{}, bool) {
+	switch val := i.(type) {
+	case driver.Valuer:
+		result, err := val.Value()
+		if err != nil {
+			return nil, false
+		}
+
+		return result, true
+	case encoding.TextMarshaler:
+		text, err := val.MarshalText()
+		if err != nil {
+			return nil, false
+		}
+
+		return string(text), true
+	case json.Marshaler:
+		data, err := val.MarshalJSON()
+		if err != nil {
+			return nil, false
+		}
+
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.UseNumber()
+
+		var result interface{}
+		if err := dec.Decode(&result); err != nil {
+			return nil, false
+		}
+
+		return result, true
+	default:
+		return nil, false
+	}
+}
@@ -0,0 +1,135 @@
+// Copyright (C) 2022 CGI France
+//
+// This file is part of the jsonline library.
+//
+// The jsonline library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The jsonline library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with the jsonline library.  If not, see <http://www.gnu.org/licenses/>.
+
+package cast_test
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/cgi-fr/jsonline/pkg/cast"
+	"github.com/stretchr/testify/assert"
+)
+
+// CountryCode implements encoding.TextMarshaler and encoding.TextUnmarshaler.
+type CountryCode string
+
+func (c CountryCode) MarshalText() ([]byte, error) {
+	return []byte(c), nil
+}
+
+func (c *CountryCode) UnmarshalText(text []byte) error {
+	if len(text) != 2 {
+		return fmt.Errorf("invalid country code %q", text)
+	}
+
+	*c = CountryCode(strings.ToUpper(string(text)))
+
+	return nil
+}
+
+// Cents implements sql.Scanner and driver.Valuer.
+type Cents struct {
+	amount int64
+}
+
+func (c *Cents) Scan(src interface{}) error {
+	i, err := cast.ToInt64(src)
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+
+	c.amount = i.(int64)
+
+	return nil
+}
+
+func (c Cents) Value() (driver.Value, error) {
+	return c.amount, nil
+}
+
+// Point implements json.Unmarshaler.
+type Point struct {
+	X, Y int
+}
+
+func (p *Point) UnmarshalJSON(data []byte) error {
+	var coords []int
+	if err := json.Unmarshal(data, &coords); err != nil || len(coords) != 2 {
+		return fmt.Errorf("invalid point %s", data)
+	}
+
+	p.X, p.Y = coords[0], coords[1]
+
+	return nil
+}
+
+// Percent is converted by a registered converter.
+type Percent float64
+
+func TestCastToCustomTypes(t *testing.T) {
+	cast.Register(Percent(0), func(i interface{}) (interface{}, error) {
+		f, err := cast.ToFloat64(i)
+		if err != nil {
+			return nil, fmt.Errorf("%w", err)
+		}
+
+		return Percent(f.(float64) / 100), nil
+	})
+
+	testdatas := []struct {
+		target   interface{}
+		value    interface{}
+		expected interface{}
+		err      error
+	}{
+		{CountryCode(""), "fr", CountryCode("FR"), nil},
+		{(*CountryCode)(nil), "fr", func() *CountryCode { c := CountryCode("FR"); return &c }(), nil},
+		{CountryCode(""), "FRA", nil, cast.ErrUnableToCast},
+		{Cents{}, json.Number("1250"), Cents{amount: 1250}, nil},
+		{Cents{}, Cents{amount: 5}, Cents{amount: 5}, nil},
+		{Point{}, []interface{}{1, 2}, Point{X: 1, Y: 2}, nil},
+		{Point{}, "1,2", nil, cast.ErrUnableToCast},
+		{Percent(0), 25, Percent(0.25), nil},
+		{Percent(0), "x", nil, cast.ErrUnableToCast},
+		{struct{}{}, "x", nil, cast.ErrUnableToCast},
+		{struct{}{}, nil, nil, cast.ErrUnableToCast},
+		{struct{}{}, struct{}{}, nil, cast.ErrUnableToCast},
+		{CountryCode(""), nil, nil, nil},
+	}
+
+	for _, td := range testdatas {
+		t.Run(fmt.Sprintf("%T(%v)", td.target, td.value), func(t *testing.T) {
+			result, err := cast.To(td.target, td.value)
+			assert.ErrorIs(t, err, td.err)
+			assert.Equal(t, td.expected, result)
+		})
+	}
+}
+
+func TestCastFromCustomTypes(t *testing.T) {
+	str, err := cast.ToString(CountryCode("FR"))
+	assert.NoError(t, err)
+	assert.Equal(t, "FR", str)
+
+	nb, err := cast.ToNumber(Cents{amount: 1250})
+	assert.NoError(t, err)
+	assert.Equal(t, json.Number("1250"), nb)
+}
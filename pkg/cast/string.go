@@ -38,6 +38,11 @@
 import (
 	"encoding/json"
 	"fmt"
+	"math/big"
+	"net"
+	"net/mail"
+	"net/netip"
+	"net/url"
 	"strconv"
 	"time"
 )
@@ -78,7 +83,26 @@ func ToString(i interface{}) (interface{}, error) {
 		return string(val), nil
 	case time.Time:
 		return val.Format(TimeStringFormat), nil
+	case time.Duration:
+		return val.String(), nil
+	case [16]byte:
+		return UUIDString(val), nil
+	case net.IP, netip.Addr, netip.Prefix, *net.IPNet, *url.URL:
+		return val.(fmt.Stringer).String(), nil
+	case *mail.Address:
+		return val.Address, nil
+	case *big.Int, *big.Float, *big.Rat:
+		nb, err := ToNumber(val)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %#v (%T)", ErrUnableToCastToString, i, i)
+		}
+
+		return string(nb.(json.Number)), nil
 	default:
+		if u, ok := underlying(val); ok {
+			return ToString(u)
+		}
+
 		return nil, fmt.Errorf("%w: %#v (%T)", ErrUnableToCastToString, i, i)
 	}
 }
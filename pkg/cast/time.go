@@ -36,7 +36,9 @@
 package cast
 
 import (
+	"encoding/json"
 	"fmt"
+	"math"
 	"time"
 )
 
@@ -131,21 +133,94 @@ func ToTime(i interface{}) (interface{}, error) {
 	}
 }
 
+// ToTimeUnit converts the value to a time, numbers are the count of units (time.Second, time.Millisecond, ...) since
+// 1970 and can be fractional.
+func ToTimeUnit(i interface{}, unit time.Duration) (interface{}, error) {
+	switch val := i.(type) {
+	case nil, time.Time:
+		return val, nil
+
+	case string:
+		t, err := time.Parse(TimeStringFormat, val)
+		if err != nil {
+			i64, err := ToInt64(val)
+			if err != nil {
+				f64, err := ToFloat64(val)
+				if err != nil {
+					return nil, fmt.Errorf("%w: %#v (%T)", ErrUnableToCastToTime, i, i)
+				}
+
+				return ToTimeUnit(f64, unit)
+			}
+
+			return ToTimeUnit(i64, unit)
+		}
+
+		return t, nil
+
+	case []byte:
+		t, err := ToTimeUnit(string(val), unit)
+		if err != nil {
+			i64, err := ToInt64(val)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %#v (%T)", ErrUnableToCastToTime, i, i)
+			}
+
+			return ToTimeUnit(i64, unit)
+		}
+
+		return t, nil
+
+	case int64:
+		perSecond := int64(time.Second / unit)
+
+		return time.Unix(val/perSecond, (val%perSecond)*int64(unit)), nil
+
+	case float64:
+		whole, frac := math.Modf(val)
+		perSecond := int64(time.Second / unit)
+
+		return time.Unix(int64(whole)/perSecond, (int64(whole)%perSecond)*int64(unit)).
+			Add(time.Duration(math.Round(frac * float64(unit)))), nil
+
+	case float32:
+		return ToTimeUnit(float64(val), unit)
+
+	case json.Number:
+		return ToTimeUnit(string(val), unit)
+
+	default:
+		i64, err := ToInt64(val)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %#v (%T)", ErrUnableToCastToTime, i, i)
+		}
+
+		return ToTimeUnit(i64, unit)
+	}
+}
+
 func ToTimestamp(i interface{}) (interface{}, error) {
+	return ToTimestampUnit(i, time.Second)
+}
+
+// ToTimestampUnit converts the value to the count of units (time.Second, time.Millisecond, ...) since 1970.
+func ToTimestampUnit(i interface{}, unit time.Duration) (interface{}, error) {
 	switch val := i.(type) {
 	case nil, int64:
 		return val, nil
 	case string:
 		t, err := time.Parse(TimeStringFormat, val)
 		if err == nil {
-			return t.Unix(), nil
+			return ToTimestampUnit(t, unit)
 		}
 
 		return nil, fmt.Errorf("%w: %#v (%T)", ErrUnableToCastToTime, i, i)
 	case []byte:
-		return ToTimestamp(string(val))
+		return ToTimestampUnit(string(val), unit)
 	case time.Time:
-		return val.Unix(), nil
+		perSecond := int64(time.Second / unit)
+
+		return val.Unix()*perSecond + int64(val.Nanosecond())/int64(unit), nil
 	default:
 		return ToInt64(val)
 	}
@@ -0,0 +1,85 @@
+// Copyright (C) 2022 CGI France
+//
+// This file is part of the jsonline library.
+//
+// The jsonline library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The jsonline library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with the jsonline library.  If not, see <http://www.gnu.org/licenses/>.
+
+package cast_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/cgi-fr/jsonline/pkg/cast"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCastToTimeUnit(t *testing.T) {
+	testdatas := []struct {
+		value    interface{}
+		unit     time.Duration
+		expected interface{}
+		err      error
+	}{
+		{nil, time.Second, nil, nil},
+		{int64(1632511314), time.Second, time.Unix(1632511314, 0), nil},
+		{int64(1632511314123), time.Millisecond, time.Unix(1632511314, 123000000), nil},
+		{int64(1632511314123456), time.Microsecond, time.Unix(1632511314, 123456000), nil},
+		{int64(1632511314123456789), time.Nanosecond, time.Unix(1632511314, 123456789), nil},
+		{int64(-1500), time.Millisecond, time.Unix(-1, -500000000), nil},
+		{float64(1632511314.5), time.Second, time.Unix(1632511314, 500000000), nil},
+		{float64(1632511314123.25), time.Millisecond, time.Unix(1632511314, 123250000), nil},
+		{json.Number("1632511314123"), time.Millisecond, time.Unix(1632511314, 123000000), nil},
+		{json.Number("1632511314.25"), time.Second, time.Unix(1632511314, 250000000), nil},
+		{"1632511314123", time.Millisecond, time.Unix(1632511314, 123000000), nil},
+		{"2021-09-24T19:21:54Z", time.Millisecond, time.Date(2021, 9, 24, 19, 21, 54, 0, time.UTC), nil},
+		{"hello", time.Millisecond, nil, cast.ErrUnableToCastToTime},
+	}
+
+	for _, td := range testdatas {
+		t.Run(fmt.Sprintf("%T(%v)/%v", td.value, td.value, td.unit), func(t *testing.T) {
+			result, err := cast.ToTimeUnit(td.value, td.unit)
+			assert.ErrorIs(t, err, td.err)
+			assert.Equal(t, td.expected, result)
+		})
+	}
+}
+
+func TestCastToTimestampUnit(t *testing.T) {
+	testdatas := []struct {
+		value    interface{}
+		unit     time.Duration
+		expected interface{}
+		err      error
+	}{
+		{nil, time.Second, nil, nil},
+		{time.Unix(1632511314, 123456789), time.Second, int64(1632511314), nil},
+		{time.Unix(1632511314, 123456789), time.Millisecond, int64(1632511314123), nil},
+		{time.Unix(1632511314, 123456789), time.Microsecond, int64(1632511314123456), nil},
+		{time.Unix(1632511314, 123456789), time.Nanosecond, int64(1632511314123456789), nil},
+		{"2021-09-24T19:21:54Z", time.Millisecond, int64(1632511314000), nil},
+		{int64(1632511314123), time.Millisecond, int64(1632511314123), nil},
+		{"hello", time.Millisecond, nil, cast.ErrUnableToCastToTime},
+	}
+
+	for _, td := range testdatas {
+		t.Run(fmt.Sprintf("%T(%v)/%v", td.value, td.value, td.unit), func(t *testing.T) {
+			result, err := cast.ToTimestampUnit(td.value, td.unit)
+			assert.ErrorIs(t, err, td.err)
+			assert.Equal(t, td.expected, result)
+		})
+	}
+}
@@ -0,0 +1,307 @@
+// Copyright (C) 2022 CGI France
+//
+// This file is part of the jsonline library.
+//
+// The jsonline library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The jsonline library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with the jsonline library.  If not, see <http://www.gnu.org/licenses/>.
+//
+// Linking this library statically or dynamically with other modules is
+// making a combined work based on this library.  Thus, the terms and
+// conditions of the GNU General Public License cover the whole
+// combination.
+//
+// As a special exception, the copyright holders of this library give you
+// permission to link this library with independent modules to produce an
+// executable, regardless of the license terms of these independent
+// modules, and to copy and distribute the resulting executable under
+// terms of your choice, provided that you also meet, for each linked
+// independent module, the terms and conditions of the license of that
+// module.  An independent module is a module which is not derived from
+// or based on this library.  If you modify this library, you may extend
+// this exception to your version of the library, but you are not
+// obligated to do so.  If you do not wish to do so, delete this
+// exception statement from your version.
+
+package jsonline
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// array is a JSON array whose elements all share the same format and rawtype, or follow the same sub template.
+type array struct {
+	values []Value
+	empty  func() Value
+	f      Format
+	typ    RawType
+	opts   *valueOptions
+}
+
+// NewValueArray create a new array, every element will be imported and exported with the given format and rawtype.
+func NewValueArray(v interface{}, f Format, rawtype RawType, opts ...ValueOption) Value {
+	result := newArray(f, rawtype, opts...)
+
+	if err := result.assign(v); err != nil {
+		result.values = nil
+	}
+
+	return result
+}
+
+func newArray(f Format, rawtype RawType, opts ...ValueOption) *array {
+	return &array{
+		values: nil,
+		empty:  func() Value { return NewValueNil(f, rawtype, opts...) },
+		f:      f,
+		typ:    rawtype,
+		opts:   newOptions(opts),
+	}
+}
+
+func newArrayOfRows(t Template) *array {
+	return &array{
+		values: nil,
+		empty:  func() Value { return t.CreateRowEmpty() },
+		f:      Auto,
+		typ:    nil,
+		opts:   nil,
+	}
+}
+
+func (a *array) clone() *array {
+	result := &array{
+		values: nil,
+		empty:  a.empty,
+		f:      a.f,
+		typ:    a.typ,
+		opts:   a.opts,
+	}
+
+	if a.values != nil {
+		result.values = make([]Value, len(a.values))
+		for i, value := range a.values {
+			result.values[i] = CloneValue(value)
+		}
+	}
+
+	return result
+}
+
+// assign replaces the elements of the array, each element is cast to the rawtype of the array (or used to fill a row
+// if the array contains rows).
+func (a *array) assign(v interface{}) error {
+	if value, ok := v.(Value); ok {
+		v = value.Raw()
+	}
+
+	if v == nil {
+		a.values = nil
+
+		return nil
+	}
+
+	elems := reflect.ValueOf(v)
+	if elems.Kind() != reflect.Slice && elems.Kind() != reflect.Array {
+		return fmt.Errorf("%w %T to array", ErrUnsupportedImportType, v)
+	}
+
+	values := make([]Value, elems.Len())
+
+	for i := 0; i < elems.Len(); i++ {
+		value, err := assign(a.empty(), elems.Index(i).Interface())
+		if err != nil {
+			return err
+		}
+
+		values[i] = value
+	}
+
+	a.values = values
+
+	return nil
+}
+
+func (a *array) GetFormat() Format {
+	return a.f
+}
+
+func (a *array) GetRawType() RawType {
+	return a.typ
+}
+
+func (a *array) Raw() interface{} {
+	if a.values == nil {
+		return nil
+	}
+
+	result := make([]interface{}, len(a.values))
+
+	for i, value := range a.values {
+		if row, ok := value.(Row); ok {
+			result[i] = row
+		} else {
+			result[i] = value.Raw()
+		}
+	}
+
+	return result
+}
+
+func (a *array) Export() (interface{}, error) {
+	if a.values == nil {
+		return a.opts.nullValue(), nil
+	}
+
+	result := make([]interface{}, len(a.values))
+
+	for i, value := range a.values {
+		exported, err := value.Export()
+		if err != nil {
+			return nil, fmt.Errorf("%w", err)
+		}
+
+		result[i] = exported
+	}
+
+	return result, nil
+}
+
+func (a *array) Import(v interface{}) error {
+	switch elems := v.(type) {
+	case nil:
+		a.values = nil
+	case []interface{}:
+		values := make([]Value, len(elems))
+
+		for i, elem := range elems {
+			values[i] = a.empty()
+			if err := values[i].Import(elem); err != nil {
+				return fmt.Errorf("%w", err)
+			}
+		}
+
+		a.values = values
+	case Value:
+		return a.Import(elems.Raw())
+	default:
+		return fmt.Errorf("%w %T to array", ErrUnsupportedImportType, v)
+	}
+
+	return nil
+}
+
+func (a *array) MarshalJSON() ([]byte, error) {
+	if a.values == nil {
+		b, err := json.Marshal(a.opts.nullValue())
+		if err != nil {
+			return nil, fmt.Errorf("%w", err)
+		}
+
+		return b, nil
+	}
+
+	res := []byte{'['}
+
+	for i, value := range a.values {
+		if i > 0 {
+			res = append(res, ',')
+		}
+
+		b, err := json.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("%w", err)
+		}
+
+		res = append(res, b...)
+	}
+
+	return append(res, ']'), nil
+}
+
+func (a *array) UnmarshalJSON(data []byte) error {
+	var raw interface{}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	if err := dec.Decode(&raw); err != nil {
+		return fmt.Errorf("can't unmarshal JSON data: %w", err)
+	}
+
+	if err := a.Import(raw); err != nil {
+		return fmt.Errorf("can't unmarshal JSON data: %w", err)
+	}
+
+	return nil
+}
+
+func (a *array) String() string {
+	b, err := a.MarshalJSON()
+	if err != nil {
+		return fmt.Sprintf("ERROR: %v", err)
+	}
+
+	return string(b)
+}
+
+func (a *array) DebugString() string {
+	sb := strings.Builder{}
+	sb.WriteString("[")
+
+	for i, value := range a.values {
+		if i > 0 {
+			sb.WriteString(";")
+		}
+
+		sb.WriteString(value.DebugString())
+	}
+
+	sb.WriteString("]")
+
+	return sb.String()
+}
+
+func (a *array) length() int {
+	return len(a.values)
+}
+
+func (a *array) element(index int) interface{} {
+	return a.values[index]
+}
+
+func (a *array) set(index int, val interface{}) error {
+	value, err := assign(a.empty(), val)
+	if err != nil {
+		return err
+	}
+
+	a.values[index] = value
+
+	return nil
+}
+
+func (a *array) setValue(index int, val Value) {
+	a.values[index] = val
+}
+
+func (a *array) importValue(index int, val interface{}) error {
+	return a.values[index].Import(val)
+}
+
+func (a *array) remove(index int) {
+	a.values = append(a.values[:index:index], a.values[index+1:]...)
+}
@@ -0,0 +1,460 @@
+// Copyright (C) 2022 CGI France
+//
+// This file is part of the jsonline library.
+//
+// The jsonline library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The jsonline library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with the jsonline library.  If not, see <http://www.gnu.org/licenses/>.
+//
+// Linking this library statically or dynamically with other modules is
+// making a combined work based on this library.  Thus, the terms and
+// conditions of the GNU General Public License cover the whole
+// combination.
+//
+// As a special exception, the copyright holders of this library give you
+// permission to link this library with independent modules to produce an
+// executable, regardless of the license terms of these independent
+// modules, and to copy and distribute the resulting executable under
+// terms of your choice, provided that you also meet, for each linked
+// independent module, the terms and conditions of the license of that
+// module.  An independent module is a module which is not derived from
+// or based on this library.  If you modify this library, you may extend
+// this exception to your version of the library, but you are not
+// obligated to do so.  If you do not wish to do so, delete this
+// exception statement from your version.
+
+package jsonline
+
+import (
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/cgi-fr/jsonline/pkg/cast"
+)
+
+// Constraint is a rule that the value of a column must satisfy.
+type Constraint interface {
+	// Check returns an error if the value doesn't satisfy the constraint, present is false if the column is missing
+	// from the input.
+	Check(value Value, present bool) error
+	fmt.Stringer
+}
+
+// Violation describes a column that doesn't satisfy a constraint.
+type Violation struct {
+	Path       string      // Path of the column, e.g. : customer.address.zip
+	Constraint string      // Name of the constraint, e.g. : required
+	Value      interface{} // Offending raw value
+	Err        error       // Reason of the violation
+}
+
+func (v Violation) Error() string {
+	return fmt.Sprintf("%s: %v", v.Path, v.Err)
+}
+
+// ValidationError is returned when a row doesn't satisfy the constraints of its template.
+type ValidationError struct {
+	Violations []Violation
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, len(e.Violations))
+	for i, violation := range e.Violations {
+		messages[i] = violation.Error()
+	}
+
+	return fmt.Sprintf("%v: %s", ErrConstraintViolation, strings.Join(messages, ", "))
+}
+
+func (e *ValidationError) Unwrap() error {
+	return ErrConstraintViolation
+}
+
+type required struct{}
+
+// Required is a constraint that rejects rows where the column is missing.
+func Required() Constraint {
+	return required{}
+}
+
+func (required) Check(value Value, present bool) error {
+	if !present {
+		return ErrMissingColumn
+	}
+
+	return nil
+}
+
+func (required) String() string {
+	return "required"
+}
+
+type notNull struct{}
+
+// NotNull is a constraint that rejects null values (a missing column is considered null).
+func NotNull() Constraint {
+	return notNull{}
+}
+
+func (notNull) Check(value Value, present bool) error {
+	if value == nil || value.Raw() == nil {
+		return ErrNullValue
+	}
+
+	return nil
+}
+
+func (notNull) String() string {
+	return "nullable"
+}
+
+type enum struct {
+	values []interface{}
+}
+
+// Enum is a constraint that only accepts the given values, values are compared by their string representation.
+func Enum(values ...interface{}) Constraint {
+	return enum{values: values}
+}
+
+func (c enum) Check(value Value, present bool) error {
+	if isNull(value) {
+		return nil
+	}
+
+	str, err := cast.ToString(value.Raw())
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrNotAllowed, err)
+	}
+
+	for _, allowed := range c.values {
+		if expected, err := cast.ToString(allowed); err == nil && expected == str {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: %v is not one of %v", ErrNotAllowed, str, c.values)
+}
+
+func (c enum) String() string {
+	return "enum"
+}
+
+type pattern struct {
+	re *regexp.Regexp
+}
+
+// Pattern is a constraint that only accepts values whose string representation matches the regular expression.
+func Pattern(re *regexp.Regexp) Constraint {
+	return pattern{re: re}
+}
+
+func (c pattern) Check(value Value, present bool) error {
+	if isNull(value) {
+		return nil
+	}
+
+	str, err := cast.ToString(value.Raw())
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrPatternMismatch, err)
+	}
+
+	if !c.re.MatchString(str.(string)) {
+		return fmt.Errorf("%w: %q does not match %q", ErrPatternMismatch, str, c.re.String())
+	}
+
+	return nil
+}
+
+func (c pattern) String() string {
+	return "pattern"
+}
+
+type bound struct {
+	limit interface{}
+	min   bool
+}
+
+// Min is a constraint that rejects numerics and dates lower than limit.
+func Min(limit interface{}) Constraint {
+	return bound{limit: limit, min: true}
+}
+
+// Max is a constraint that rejects numerics and dates greater than limit.
+func Max(limit interface{}) Constraint {
+	return bound{limit: limit, min: false}
+}
+
+func (c bound) Check(value Value, present bool) error {
+	if isNull(value) {
+		return nil
+	}
+
+	cmp, err := compare(value, c.limit)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrOutOfRange, err)
+	}
+
+	if c.min && cmp < 0 {
+		return fmt.Errorf("%w: %v is lower than %v", ErrOutOfRange, value, c.limit)
+	}
+
+	if !c.min && cmp > 0 {
+		return fmt.Errorf("%w: %v is greater than %v", ErrOutOfRange, value, c.limit)
+	}
+
+	return nil
+}
+
+func (c bound) String() string {
+	if c.min {
+		return "min"
+	}
+
+	return "max"
+}
+
+// compare compares the value to the limit, dates are compared as times (numeric limits of timestamps are in the unit
+// of the timestamp) and other values as numbers.
+func compare(value Value, limit interface{}) (int, error) {
+	_, isTime := value.Raw().(time.Time)
+	unit, isTimestamp := epochUnit(value.GetFormat())
+
+	switch {
+	case value.GetFormat() == Date:
+		t1, err1 := toDateTime(value.Raw())
+		t2, err2 := toDateTime(limit)
+
+		return compareTimes(t1, t2, err1, err2)
+	case isTimestamp && isTime:
+		t1, err1 := cast.ToTime(value.Raw())
+		t2, err2 := cast.ToTimeUnit(limit, unit)
+
+		return compareTimes(t1, t2, err1, err2)
+	case value.GetFormat() == DateTime || isTime:
+		t1, err1 := cast.ToTime(value.Raw())
+		t2, err2 := cast.ToTime(limit)
+
+		return compareTimes(t1, t2, err1, err2)
+	default:
+		n1, err := toBigFloat(value.Raw())
+		if err != nil {
+			return 0, err
+		}
+
+		n2, err := toBigFloat(limit)
+		if err != nil {
+			return 0, err
+		}
+
+		return n1.Cmp(n2), nil
+	}
+}
+
+func toDateTime(v interface{}) (interface{}, error) {
+	str, err := cast.ToDate(v)
+	if err != nil {
+		return nil, fmt.Errorf("%w", err)
+	}
+
+	t, err := time.Parse("2006-01-02", str.(string))
+	if err != nil {
+		return nil, fmt.Errorf("%w", err)
+	}
+
+	return t, nil
+}
+
+func compareTimes(t1, t2 interface{}, err1, err2 error) (int, error) {
+	if err1 != nil {
+		return 0, err1
+	}
+
+	if err2 != nil {
+		return 0, err2
+	}
+
+	time1, _ := t1.(time.Time)
+	time2, _ := t2.(time.Time)
+
+	switch {
+	case time1.Before(time2):
+		return -1, nil
+	case time1.After(time2):
+		return 1, nil
+	default:
+		return 0, nil
+	}
+}
+
+func toBigFloat(v interface{}) (*big.Float, error) {
+	nb, err := cast.ToNumber(v)
+	if err != nil {
+		return nil, fmt.Errorf("%w", err)
+	}
+
+	f, ok := new(big.Float).SetString(fmt.Sprint(nb))
+	if !ok {
+		return nil, fmt.Errorf("%w: %v is not a number", cast.ErrUnableToCastToNumber, v)
+	}
+
+	return f, nil
+}
+
+type length struct {
+	limit int
+	min   bool
+}
+
+// MinLength is a constraint that rejects strings (in characters), binaries (in bytes) and arrays (in elements) shorter
+// than limit.
+func MinLength(limit int) Constraint {
+	return length{limit: limit, min: true}
+}
+
+// MaxLength is a constraint that rejects strings (in characters), binaries (in bytes) and arrays (in elements) longer
+// than limit.
+func MaxLength(limit int) Constraint {
+	return length{limit: limit, min: false}
+}
+
+func (c length) Check(value Value, present bool) error {
+	if isNull(value) {
+		return nil
+	}
+
+	var size int
+
+	switch raw := value.Raw().(type) {
+	case []interface{}:
+		size = len(raw)
+	default:
+		if value.GetFormat() == Binary {
+			b, err := cast.ToBinary(raw)
+			if err != nil {
+				return fmt.Errorf("%w: %v", ErrInvalidLength, err)
+			}
+
+			size = len(b.([]byte))
+		} else {
+			str, err := cast.ToString(raw)
+			if err != nil {
+				return fmt.Errorf("%w: %v", ErrInvalidLength, err)
+			}
+
+			size = utf8.RuneCountInString(str.(string))
+		}
+	}
+
+	if c.min && size < c.limit {
+		return fmt.Errorf("%w: length %d is lower than %d", ErrInvalidLength, size, c.limit)
+	}
+
+	if !c.min && size > c.limit {
+		return fmt.Errorf("%w: length %d is greater than %d", ErrInvalidLength, size, c.limit)
+	}
+
+	return nil
+}
+
+func (c length) String() string {
+	if c.min {
+		return "minLength"
+	}
+
+	return "maxLength"
+}
+
+// validate checks the constraints of the row and of its sub rows, paths of violations are prefixed with prefix.
+//
+//nolint:cyclop
+func (r *row) validate(prefix string) []Violation {
+	violations := []Violation{}
+
+	var (
+		constraints map[string][]Constraint
+		constrained []string
+	)
+
+	if r.schema != nil {
+		constraints, constrained = r.schema.constraints, r.schema.constrained
+	}
+
+	check := func(key string, value Value) {
+		_, present := r.present[key]
+
+		for _, constraint := range constraints[key] {
+			if err := constraint.Check(value, present); err != nil {
+				var raw interface{}
+				if value != nil {
+					raw = value.Raw()
+				}
+
+				violations = append(violations, Violation{
+					Path:       joinPath(prefix, key),
+					Constraint: constraint.String(),
+					Value:      raw,
+					Err:        err,
+				})
+			}
+		}
+	}
+
+	for e := r.l.Front(); e != nil; e = e.Next() {
+		key, _ := e.Value.(string)
+		value := r.m[key]
+
+		check(key, value)
+
+		// sub rows of the template missing from the input are not validated, the parent column reports them
+		if _, present := r.present[key]; r.schema != nil && !present {
+			continue
+		}
+
+		switch typed := value.(type) {
+		case *row:
+			violations = append(violations, typed.validate(joinPath(prefix, key))...)
+		case *array:
+			for i, elem := range typed.values {
+				if sub, ok := elem.(*row); ok {
+					violations = append(violations, sub.validate(fmt.Sprintf("%s[%d]", joinPath(prefix, key), i))...)
+				}
+			}
+		}
+	}
+
+	// columns constrained but deleted from the row
+	for _, key := range constrained {
+		if _, exist := r.m[key]; !exist {
+			check(key, nil)
+		}
+	}
+
+	return violations
+}
+
+// joinPath appends a key to a path, keys containing special characters are quoted.
+func joinPath(prefix string, key string) string {
+	if strings.ContainsAny(key, `.[]"'*`) || key == "" {
+		key = fmt.Sprintf("%q", key)
+	}
+
+	if prefix == "" {
+		return key
+	}
+
+	return prefix + "." + key
+}
@@ -35,8 +35,10 @@
 package jsonline
 
 import (
-	"encoding/base64"
+	"encoding/json"
 	"fmt"
+	"strconv"
+	"time"
 
 	"github.com/cgi-fr/jsonline/pkg/cast"
 )
@@ -59,6 +61,74 @@ func exportToNumber(val interface{}) (interface{}, error) {
 	return nb, nil
 }
 
+func exportToDecimal(val interface{}, opts *valueOptions) (interface{}, error) {
+	r, err := toDecimal(val, opts)
+	if err != nil {
+		return nil, fmt.Errorf("%w %T to Decimal format: %v", ErrUnsupportedExportType, val, err)
+	}
+
+	return json.Number(decimalString(r, opts)), nil
+}
+
+func exportToDuration(val interface{}, opts *valueOptions) (interface{}, error) {
+	d, err := cast.ToDurationUnit(val, durationUnit(opts))
+	if err != nil {
+		return nil, fmt.Errorf("%w %T to Duration format: %v", ErrUnsupportedExportType, val, err)
+	}
+
+	return durationNotation(d.(time.Duration), opts), nil
+}
+
+func exportToSemantic(val interface{}, f Format) (interface{}, error) {
+	str, err := canonicalString(val, f)
+	if err != nil {
+		return nil, fmt.Errorf("%w %T to %s format: %v", ErrUnsupportedExportType, val, formatName(f), err)
+	}
+
+	return str, nil
+}
+
+func exportToJSON(val interface{}, opts *valueOptions) (interface{}, error) {
+	doc, err := decodeEmbedded(val, opts)
+	if err != nil {
+		return nil, fmt.Errorf("%w %T to JSON format: %v", ErrUnsupportedExportType, val, err)
+	}
+
+	str, err := encodeEmbedded(doc)
+	if err != nil {
+		return nil, fmt.Errorf("%w %T to JSON format: %v", ErrUnsupportedExportType, val, err)
+	}
+
+	return str, nil
+}
+
+// durationUnit returns the unit of numeric durations.
+func durationUnit(opts *valueOptions) time.Duration {
+	if opts != nil && opts.notation == DurationMillis {
+		return time.Millisecond
+	}
+
+	return time.Second
+}
+
+// durationNotation returns the representation of the duration in the notation of the options.
+func durationNotation(d time.Duration, opts *valueOptions) interface{} {
+	notation := DurationGo
+	if opts != nil {
+		notation = opts.notation
+	}
+
+	switch notation {
+	case DurationISO8601:
+		return cast.ISO8601Duration(d)
+	case DurationSeconds, DurationMillis:
+		return json.Number(strconv.FormatFloat(float64(d)/float64(durationUnit(opts)), 'f', -1, 64))
+	case DurationGo:
+	}
+
+	return d.String()
+}
+
 func exportToBool(val interface{}) (interface{}, error) {
 	b, err := cast.ToBool(val)
 	if err != nil {
@@ -68,16 +138,20 @@ func exportToBool(val interface{}) (interface{}, error) {
 	return b, nil
 }
 
-func exportToBinary(val interface{}) (interface{}, error) {
-	b, err := cast.ToBinary(val)
+func exportToBinary(val interface{}, opts *valueOptions) (interface{}, error) {
+	b, err := cast.ToBinaryLayout(val, opts.byteLayout())
 	if err != nil {
 		return nil, fmt.Errorf("%w %T to Binary format: %v", ErrUnsupportedExportType, val, err)
 	}
 
-	return base64.StdEncoding.EncodeToString(b.([]byte)), nil
+	return opts.binaryEncoding().codec().EncodeToString(b.([]byte)), nil
 }
 
-func exportToDate(val interface{}) (interface{}, error) {
+func exportToDate(val interface{}, opts *valueOptions) (interface{}, error) {
+	if opts != nil {
+		return exportWithOptions(val, opts, "2006-01-02")
+	}
+
 	t, err := cast.ToDate(val)
 	if err != nil {
 		return nil, fmt.Errorf("%w %T to Date format: %v", ErrUnsupportedExportType, val, err)
@@ -91,7 +165,11 @@ func exportToDate(val interface{}) (interface{}, error) {
 	return str, nil
 }
 
-func exportToDateTime(val interface{}) (interface{}, error) {
+func exportToDateTime(val interface{}, opts *valueOptions) (interface{}, error) {
+	if opts != nil {
+		return exportWithOptions(val, opts, cast.TimeStringFormat)
+	}
+
 	t, err := cast.ToTime(val)
 	if err != nil {
 		return nil, fmt.Errorf("%w %T to DateTime format: %v", ErrUnsupportedExportType, val, err)
@@ -105,6 +183,50 @@ func exportToDateTime(val interface{}) (interface{}, error) {
 	return str, nil
 }
 
+// exportWithOptions formats the value with the first layout of the options (or the default layout), in the timezone of
+// the options. Strings are parsed with the layouts of the options or with the default layout.
+func exportWithOptions(val interface{}, opts *valueOptions, defaultLayout string) (interface{}, error) {
+	t, err := parseTimeWithOptions(val, opts)
+	if err != nil {
+		t = val
+	}
+
+	if str, ok := t.(string); ok {
+		zone := opts.assumed()
+		if opts.location != nil {
+			zone = opts.location
+		}
+
+		t, err = time.ParseInLocation(defaultLayout, str, zone)
+	} else {
+		t, err = cast.ToTime(t)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("%w %T to layout %q: %v", ErrUnsupportedExportType, val, defaultLayout, err)
+	}
+
+	result := t.(time.Time)
+	if opts.location != nil {
+		result = result.In(opts.location)
+	}
+
+	if len(opts.layouts) > 0 {
+		return result.Format(opts.layouts[0]), nil
+	}
+
+	return result.Format(defaultLayout), nil
+}
+
+func exportToTimestampUnit(val interface{}, unit time.Duration) (interface{}, error) {
+	i64, err := cast.ToTimestampUnit(val, unit)
+	if err != nil {
+		return nil, fmt.Errorf("%w %T to Timestamp format: %v", ErrUnsupportedExportType, val, err)
+	}
+
+	return i64, nil
+}
+
 func exportToTimestamp(val interface{}) (interface{}, error) {
 	i64, err := cast.ToTimestamp(val)
 	if err != nil {
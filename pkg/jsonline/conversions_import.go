@@ -35,8 +35,10 @@
 package jsonline
 
 import (
-	"encoding/base64"
+	"encoding/json"
 	"fmt"
+	"math/big"
+	"time"
 
 	"github.com/cgi-fr/jsonline/pkg/cast"
 )
@@ -81,6 +83,95 @@ func importFromNumeric(val interface{}, targetType RawType) (interface{}, error)
 	}
 }
 
+// importFromDecimal imports an exact number rounded to the scale of the options, the raw value is a json.Number unless
+// a rawtype is set (e.g. : *big.Rat).
+func importFromDecimal(val interface{}, targetType RawType, opts *valueOptions) (interface{}, error) {
+	r, err := toDecimal(val, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	switch targetType.(type) {
+	case nil:
+		return json.Number(decimalString(r, opts)), nil
+
+	case *big.Rat:
+		return r, nil
+
+	default:
+		i, err := cast.To(targetType, json.Number(decimalString(r, opts)))
+		if err != nil {
+			return nil, fmt.Errorf("%w %T to %T format: %v", ErrUnsupportedImportType, val, targetType, err)
+		}
+
+		return i, nil
+	}
+}
+
+// importFromDuration imports a duration, the raw value is a time.Duration unless a rawtype is set, then it holds the
+// duration in the notation of the options, or the number of seconds (milliseconds with DurationMillis) for numeric
+// rawtypes.
+func importFromDuration(val interface{}, targetType RawType, opts *valueOptions) (interface{}, error) {
+	d, err := cast.ToDurationUnit(val, durationUnit(opts))
+	if err != nil {
+		return nil, fmt.Errorf("%w %T to %T format: %v", ErrUnsupportedImportType, val, targetType, err)
+	}
+
+	switch targetType.(type) {
+	case nil, time.Duration:
+		return d, nil
+
+	default:
+		i, err := cast.To(targetType, durationNotation(d.(time.Duration), opts))
+		if err != nil {
+			count := float64(d.(time.Duration)) / float64(durationUnit(opts))
+			if i, err = cast.To(targetType, count); err != nil {
+				return nil, fmt.Errorf("%w %T to %T format: %v", ErrUnsupportedImportType, val, targetType, err)
+			}
+		}
+
+		return i, nil
+	}
+}
+
+// importFromSemantic imports a value validated by a semantic format (UUID, IPv4, ...), the raw value is the canonical
+// string unless a rawtype is set (e.g. : [16]byte, netip.Addr, *url.URL).
+func importFromSemantic(val interface{}, targetType RawType, f Format) (interface{}, error) {
+	str, err := canonicalString(val, f)
+	if err != nil {
+		return nil, fmt.Errorf("%w %T to %s format: %v", ErrUnsupportedImportType, val, formatName(f), err)
+	}
+
+	switch targetType.(type) {
+	case nil:
+		return str, nil
+
+	default:
+		i, err := cast.To(targetType, str)
+		if err != nil {
+			return nil, fmt.Errorf("%w %T to %T format: %v", ErrUnsupportedImportType, val, targetType, err)
+		}
+
+		return i, nil
+	}
+}
+
+// importFromJSON imports a JSON document embedded in a string, the raw value is the document (a Row for objects)
+// unless a rawtype is set (e.g. : string).
+func importFromJSON(val interface{}, targetType RawType, opts *valueOptions) (interface{}, error) {
+	doc, err := decodeEmbedded(val, opts)
+	if err != nil {
+		return nil, fmt.Errorf("%w %T to JSON format: %v", ErrUnsupportedImportType, val, err)
+	}
+
+	i, err := embeddedRaw(doc, targetType)
+	if err != nil {
+		return nil, fmt.Errorf("%w %T to %T format: %v", ErrUnsupportedImportType, val, targetType, err)
+	}
+
+	return i, nil
+}
+
 func importFromBoolean(val interface{}, targetType RawType) (interface{}, error) {
 	switch targetType.(type) {
 	case nil:
@@ -101,13 +192,13 @@ func importFromBoolean(val interface{}, targetType RawType) (interface{}, error)
 	}
 }
 
-func importFromBinary(val interface{}, targetType RawType) (interface{}, error) {
+func importFromBinary(val interface{}, targetType RawType, opts *valueOptions) (interface{}, error) {
 	str, err := cast.ToString(val)
 	if err != nil {
 		return nil, fmt.Errorf("%w %T to %T format: %v", ErrUnsupportedImportType, val, targetType, err)
 	}
 
-	b, err := base64.StdEncoding.DecodeString(str.(string))
+	b, err := opts.binaryEncoding().codec().DecodeString(str.(string))
 	if err != nil {
 		return nil, fmt.Errorf("%w %T to %T format: %v", ErrUnsupportedImportType, val, targetType, err)
 	}
@@ -117,7 +208,7 @@ func importFromBinary(val interface{}, targetType RawType) (interface{}, error)
 		return b, nil
 
 	default:
-		i, err := cast.To(targetType, b)
+		i, err := cast.FromBinaryLayout(targetType, b, opts.byteLayout())
 		if err != nil {
 			return nil, fmt.Errorf("%w %T to %T format: %v", ErrUnsupportedImportType, b, targetType, err)
 		}
@@ -126,7 +217,12 @@ func importFromBinary(val interface{}, targetType RawType) (interface{}, error)
 	}
 }
 
-func importFromDate(val interface{}, targetType RawType) (interface{}, error) {
+func importFromDate(val interface{}, targetType RawType, opts *valueOptions) (interface{}, error) {
+	val, err := parseTimeWithOptions(val, opts)
+	if err != nil {
+		return nil, err
+	}
+
 	switch targetType.(type) {
 	case nil:
 		t, err := cast.ToDate(val)
@@ -137,7 +233,16 @@ func importFromDate(val interface{}, targetType RawType) (interface{}, error) {
 		return t, nil
 
 	default:
+		if str, ok := val.(string); ok {
+			if t, err := time.Parse("2006-01-02", str); err == nil {
+				val = t
+			}
+		}
+
 		i, err := cast.To(targetType, val)
+		if t, ok := val.(time.Time); ok && err != nil {
+			i, err = cast.To(targetType, t.Unix()) // numeric raw types hold timestamps
+		}
 		if err != nil {
 			return nil, fmt.Errorf("%w %T to %T format: %v", ErrUnsupportedImportType, val, targetType, err)
 		}
@@ -146,7 +251,12 @@ func importFromDate(val interface{}, targetType RawType) (interface{}, error) {
 	}
 }
 
-func importFromDateTime(val interface{}, targetType RawType) (interface{}, error) {
+func importFromDateTime(val interface{}, targetType RawType, opts *valueOptions) (interface{}, error) {
+	val, err := parseTimeWithOptions(val, opts)
+	if err != nil {
+		return nil, err
+	}
+
 	switch targetType.(type) {
 	case nil:
 		t, err := cast.ToTime(val)
@@ -158,6 +268,9 @@ func importFromDateTime(val interface{}, targetType RawType) (interface{}, error
 
 	default:
 		i, err := cast.To(targetType, val)
+		if t, ok := val.(time.Time); ok && err != nil {
+			i, err = cast.To(targetType, t.Unix()) // numeric raw types hold timestamps
+		}
 		if err != nil {
 			return nil, fmt.Errorf("%w %T to %T format: %v", ErrUnsupportedImportType, val, targetType, err)
 		}
@@ -166,6 +279,8 @@ func importFromDateTime(val interface{}, targetType RawType) (interface{}, error
 	}
 }
 
+// importFromTimestamp imports a count of seconds since 1970, the raw value is an int64 unless a rawtype is set, a
+// time.Time rawtype keeps the fractional seconds.
 func importFromTimestamp(val interface{}, targetType RawType) (interface{}, error) {
 	switch targetType.(type) {
 	case nil:
@@ -176,6 +291,9 @@ func importFromTimestamp(val interface{}, targetType RawType) (interface{}, erro
 
 		return i64, nil
 
+	case time.Time:
+		return importFromTimestampUnit(val, targetType, time.Second)
+
 	default:
 		i, err := cast.To(targetType, val)
 		if err != nil {
@@ -185,3 +303,28 @@ func importFromTimestamp(val interface{}, targetType RawType) (interface{}, erro
 		return i, nil
 	}
 }
+
+// importFromTimestampUnit imports a count of units since 1970, times are kept as raw values so that they can be
+// exported to any other temporal format, numeric raw types hold the count of units.
+func importFromTimestampUnit(val interface{}, targetType RawType, unit time.Duration) (interface{}, error) {
+	t, err := cast.ToTimeUnit(val, unit)
+	if err != nil {
+		return nil, fmt.Errorf("%w %T to %T format: %v", ErrUnsupportedImportType, val, targetType, err)
+	}
+
+	switch targetType.(type) {
+	case nil, time.Time:
+		return t, nil
+
+	default:
+		i, err := cast.To(targetType, t)
+		if err != nil {
+			i64, _ := cast.ToTimestampUnit(t, unit)
+			if i, err = cast.To(targetType, i64); err != nil {
+				return nil, fmt.Errorf("%w %T to %T format: %v", ErrUnsupportedImportType, val, targetType, err)
+			}
+		}
+
+		return i, nil
+	}
+}
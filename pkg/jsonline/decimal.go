@@ -0,0 +1,124 @@
+// Copyright (C) 2022 CGI France
+//
+// This file is part of the jsonline library.
+//
+// The jsonline library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The jsonline library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with the jsonline library.  If not, see <http://www.gnu.org/licenses/>.
+//
+// Linking this library statically or dynamically with other modules is
+// making a combined work based on this library.  Thus, the terms and
+// conditions of the GNU General Public License cover the whole
+// combination.
+//
+// As a special exception, the copyright holders of this library give you
+// permission to link this library with independent modules to produce an
+// executable, regardless of the license terms of these independent
+// modules, and to copy and distribute the resulting executable under
+// terms of your choice, provided that you also meet, for each linked
+// independent module, the terms and conditions of the license of that
+// module.  An independent module is a module which is not derived from
+// or based on this library.  If you modify this library, you may extend
+// this exception to your version of the library, but you are not
+// obligated to do so.  If you do not wish to do so, delete this
+// exception statement from your version.
+
+package jsonline
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/cgi-fr/jsonline/pkg/cast"
+)
+
+//nolint:gochecknoglobals
+var (
+	ten = big.NewInt(10)
+	one = big.NewInt(1)
+)
+
+// toDecimal converts the value to a rational number rounded to the scale of the options, an error is returned if the
+// number has more digits than the precision of the options.
+func toDecimal(val interface{}, opts *valueOptions) (*big.Rat, error) {
+	i, err := cast.ToBigRat(val)
+	if err != nil || i == nil {
+		return nil, fmt.Errorf("%w %T to Decimal format: %v", ErrUnsupportedImportType, val, err)
+	}
+
+	r, _ := i.(*big.Rat)
+	if opts == nil || opts.digits == nil {
+		return r, nil
+	}
+
+	unscaled := round(r, opts.digits.scale, opts.rounding)
+
+	if opts.digits.precision > 0 && len(new(big.Int).Abs(unscaled).String()) > opts.digits.precision {
+		return nil, fmt.Errorf("%w: %v has more than %d digits", ErrOutOfPrecision, val, opts.digits.precision)
+	}
+
+	return new(big.Rat).SetFrac(unscaled, new(big.Int).Exp(ten, big.NewInt(int64(opts.digits.scale)), nil)), nil
+}
+
+// decimalString returns the decimal representation of the number, with the scale of the options if any.
+func decimalString(r *big.Rat, opts *valueOptions) string {
+	if opts == nil || opts.digits == nil {
+		nb, _ := cast.ToNumber(r)
+
+		return fmt.Sprint(nb)
+	}
+
+	return r.FloatString(opts.digits.scale)
+}
+
+// round returns the number multiplied by 10^scale and rounded to an integer with the rounding mode.
+//
+//nolint:cyclop
+func round(r *big.Rat, scale int, mode big.RoundingMode) *big.Int {
+	num := new(big.Int).Mul(r.Num(), new(big.Int).Exp(ten, big.NewInt(int64(scale)), nil))
+	quo, rem := new(big.Int).QuoRem(num, r.Denom(), new(big.Int))
+
+	if rem.Sign() == 0 {
+		return quo
+	}
+
+	away := false
+
+	switch mode {
+	case big.ToZero:
+	case big.AwayFromZero:
+		away = true
+	case big.ToNegativeInf:
+		away = r.Sign() < 0
+	case big.ToPositiveInf:
+		away = r.Sign() > 0
+	case big.ToNearestEven, big.ToNearestAway:
+		switch half := new(big.Int).Mul(new(big.Int).Abs(rem), big.NewInt(2)).Cmp(r.Denom()); {
+		case half > 0:
+			away = true
+		case half == 0 && mode == big.ToNearestAway:
+			away = true
+		case half == 0:
+			away = quo.Bit(0) == 1
+		}
+	}
+
+	if !away {
+		return quo
+	}
+
+	if r.Sign() < 0 {
+		return quo.Sub(quo, one)
+	}
+
+	return quo.Add(quo, one)
+}
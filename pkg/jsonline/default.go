@@ -0,0 +1,161 @@
+// Copyright (C) 2022 CGI France
+//
+// This file is part of the jsonline library.
+//
+// The jsonline library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The jsonline library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with the jsonline library.  If not, see <http://www.gnu.org/licenses/>.
+//
+// Linking this library statically or dynamically with other modules is
+// making a combined work based on this library.  Thus, the terms and
+// conditions of the GNU General Public License cover the whole
+// combination.
+//
+// As a special exception, the copyright holders of this library give you
+// permission to link this library with independent modules to produce an
+// executable, regardless of the license terms of these independent
+// modules, and to copy and distribute the resulting executable under
+// terms of your choice, provided that you also meet, for each linked
+// independent module, the terms and conditions of the license of that
+// module.  An independent module is a module which is not derived from
+// or based on this library.  If you modify this library, you may extend
+// this exception to your version of the library, but you are not
+// obligated to do so.  If you do not wish to do so, delete this
+// exception statement from your version.
+
+package jsonline
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultValue provides the value of a column when it is missing or null in the input.
+type DefaultValue interface {
+	// Value returns the value to use for the column.
+	Value() interface{}
+	// Definition returns the default value as written in a row definition, e.g. : "now()", "sequence(1)".
+	Definition() interface{}
+}
+
+type literal struct {
+	v interface{}
+}
+
+// Literal is a default value that always returns v.
+func Literal(v interface{}) DefaultValue {
+	return literal{v: v}
+}
+
+func (d literal) Value() interface{} {
+	return d.v
+}
+
+func (d literal) Definition() interface{} {
+	return d.v
+}
+
+type now struct{}
+
+// Now is a default value that returns the current time.
+func Now() DefaultValue {
+	return now{}
+}
+
+func (d now) Value() interface{} {
+	return time.Now()
+}
+
+func (d now) Definition() interface{} {
+	return "now()"
+}
+
+type sequence struct {
+	start int64
+	next  *int64
+}
+
+// Sequence is a default value that returns start, then start+1, start+2 and so on each time it is used.
+func Sequence(start int64) DefaultValue {
+	next := start - 1
+
+	return sequence{start: start, next: &next}
+}
+
+func (d sequence) Value() interface{} {
+	return atomic.AddInt64(d.next, 1)
+}
+
+func (d sequence) Definition() interface{} {
+	return fmt.Sprintf("sequence(%d)", d.start)
+}
+
+// applyDefaults sets the default values of missing or null columns, in the row and in its sub rows.
+//
+//nolint:cyclop
+func applyDefaults(r Row) error {
+	typed, ok := r.(*row)
+	if !ok {
+		return nil
+	}
+
+	if typed.schema != nil {
+		for _, key := range typed.schema.defaulted {
+			value, exist := typed.m[key]
+			if exist && value != nil && value.Raw() != nil {
+				continue
+			}
+
+			def := typed.schema.defaults[key].Value()
+
+			if !exist {
+				typed.keys[key] = typed.l.PushBack(key)
+			}
+
+			// a column filled by a default value satisfies the Required constraint
+			typed.mark(key)
+
+			if value == nil {
+				typed.m[key] = NewValueAuto(def)
+
+				continue
+			}
+
+			assigned, err := assign(value, def)
+			if err != nil {
+				return err
+			}
+
+			typed.m[key] = assigned
+		}
+	}
+
+	for _, value := range typed.m {
+		switch sub := value.(type) {
+		case *row:
+			if err := applyDefaults(sub); err != nil {
+				return err
+			}
+		case *array:
+			for _, elem := range sub.values {
+				if elem, ok := elem.(Row); ok {
+					if err := applyDefaults(elem); err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,891 @@
+// Copyright (C) 2022 CGI France
+//
+// This file is part of the jsonline library.
+//
+// The jsonline library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The jsonline library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with the jsonline library.  If not, see <http://www.gnu.org/licenses/>.
+//
+// Linking this library statically or dynamically with other modules is
+// making a combined work based on this library.  Thus, the terms and
+// conditions of the GNU General Public License cover the whole
+// combination.
+//
+// As a special exception, the copyright holders of this library give you
+// permission to link this library with independent modules to produce an
+// executable, regardless of the license terms of these independent
+// modules, and to copy and distribute the resulting executable under
+// terms of your choice, provided that you also meet, for each linked
+// independent module, the terms and conditions of the license of that
+// module.  An independent module is a module which is not derived from
+// or based on this library.  If you modify this library, you may extend
+// this exception to your version of the library, but you are not
+// obligated to do so.  If you do not wish to do so, delete this
+// exception statement from your version.
+
+package jsonline
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"net/mail"
+	"net/netip"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cgi-fr/jsonline/pkg/cast"
+	"gopkg.in/yaml.v3"
+)
+
+// RowDefinition declares an input template and an output template, as written in row.yml files. Timezones and null
+// policies declared at the row level apply to every column unless overridden by the column.
+type RowDefinition struct {
+	ExtraColumns   string             `yaml:"extra-columns,omitempty" json:"extra-columns,omitempty"`
+	Timezone       string             `yaml:"tz,omitempty" json:"tz,omitempty"`
+	AssumeTimezone string             `yaml:"assume-tz,omitempty" json:"assume-tz,omitempty"`
+	OmitNull       *bool              `yaml:"omit-null,omitempty" json:"omit-null,omitempty"`
+	EmptyAsNull    *bool              `yaml:"empty-as-null,omitempty" json:"empty-as-null,omitempty"`
+	NullIf         []interface{}      `yaml:"null-if,omitempty" json:"null-if,omitempty"`
+	NullAs         interface{}        `yaml:"null-as,omitempty" json:"null-as,omitempty"`
+	Columns        []ColumnDefinition `yaml:"columns" json:"columns"`
+}
+
+// ColumnDefinition declares a column of a RowDefinition, constraints and defaults apply to the input template only.
+type ColumnDefinition struct {
+	Name           string             `yaml:"name" json:"name"`
+	Input          string             `yaml:"input,omitempty" json:"input,omitempty"`
+	Output         string             `yaml:"output,omitempty" json:"output,omitempty"`
+	Array          bool               `yaml:"array,omitempty" json:"array,omitempty"`
+	Required       bool               `yaml:"required,omitempty" json:"required,omitempty"`
+	Nullable       *bool              `yaml:"nullable,omitempty" json:"nullable,omitempty"`
+	Default        interface{}        `yaml:"default,omitempty" json:"default,omitempty"`
+	Enum           []interface{}      `yaml:"enum,omitempty" json:"enum,omitempty"`
+	Pattern        string             `yaml:"pattern,omitempty" json:"pattern,omitempty"`
+	Min            interface{}        `yaml:"min,omitempty" json:"min,omitempty"`
+	Max            interface{}        `yaml:"max,omitempty" json:"max,omitempty"`
+	MinLength      *int               `yaml:"minLength,omitempty" json:"minLength,omitempty"`
+	MaxLength      *int               `yaml:"maxLength,omitempty" json:"maxLength,omitempty"`
+	Timezone       string             `yaml:"tz,omitempty" json:"tz,omitempty"`
+	AssumeTimezone string             `yaml:"assume-tz,omitempty" json:"assume-tz,omitempty"`
+	OmitNull       *bool              `yaml:"omit-null,omitempty" json:"omit-null,omitempty"`
+	EmptyAsNull    *bool              `yaml:"empty-as-null,omitempty" json:"empty-as-null,omitempty"`
+	NullIf         []interface{}      `yaml:"null-if,omitempty" json:"null-if,omitempty"`
+	NullAs         interface{}        `yaml:"null-as,omitempty" json:"null-as,omitempty"`
+	ExtraColumns   string             `yaml:"extra-columns,omitempty" json:"extra-columns,omitempty"`
+	Columns        []ColumnDefinition `yaml:"columns,omitempty" json:"columns,omitempty"`
+}
+
+//nolint:gochecknoglobals
+var typeRegistry = map[string]RawType{
+	"int":           int(0),
+	"int64":         int64(0),
+	"int32":         int32(0),
+	"int16":         int16(0),
+	"int8":          int8(0),
+	"uint":          uint(0),
+	"uint64":        uint64(0),
+	"uint32":        uint32(0),
+	"uint16":        uint16(0),
+	"uint8":         uint8(0),
+	"float64":       float64(0),
+	"float32":       float32(0),
+	"bool":          bool(true),
+	"byte":          byte(0),
+	"rune":          rune(0),
+	"string":        string(""),
+	"[]byte":        []byte{},
+	"time.Time":     time.Time{},
+	"time.Duration": time.Duration(0),
+	"json.Number":   json.Number(""),
+	"*big.Int":      (*big.Int)(nil),
+	"*big.Float":    (*big.Float)(nil),
+	"*big.Rat":      (*big.Rat)(nil),
+	"[16]byte":      [16]byte{},
+	"net.IP":        net.IP{},
+	"netip.Addr":    netip.Addr{},
+	"netip.Prefix":  netip.Prefix{},
+	"*net.IPNet":    (*net.IPNet)(nil),
+	"*url.URL":      (*url.URL)(nil),
+	"*mail.Address": (*mail.Address)(nil),
+}
+
+//nolint:gochecknoglobals
+var formatRegistry = map[string]Format{
+	"string":       String,
+	"numeric":      Numeric,
+	"boolean":      Boolean,
+	"binary":       Binary,
+	"date":         Date,
+	"datetime":     DateTime,
+	"timestamp":    Timestamp,
+	"timestamp_ms": TimestampMilli,
+	"timestamp_us": TimestampMicro,
+	"timestamp_ns": TimestampNano,
+	"decimal":      Decimal,
+	"duration":     Duration,
+	"uuid":         UUID,
+	"ipv4":         IPv4,
+	"ipv6":         IPv6,
+	"cidr":         CIDR,
+	"url":          URL,
+	"email":        Email,
+	"json":         JSON,
+	"auto":         Auto,
+	"hidden":       Hidden,
+}
+
+//nolint:gochecknoglobals
+var notationRegistry = map[string]DurationNotation{
+	"go":      DurationGo,
+	"iso8601": DurationISO8601,
+	"s":       DurationSeconds,
+	"ms":      DurationMillis,
+}
+
+//nolint:gochecknoglobals
+var encodingRegistry = map[string]BinaryEncoding{
+	"base64":       Base64,
+	"base64raw":    Base64Raw,
+	"base64url":    Base64URL,
+	"base64urlraw": Base64URLRaw,
+	"base32":       Base32,
+	"base32raw":    Base32Raw,
+	"hex":          Hex,
+}
+
+//nolint:gochecknoglobals
+var byteLayoutRegistry = map[string]cast.ByteLayout{
+	"le":      cast.LittleEndian,
+	"be":      cast.BigEndian,
+	"network": cast.BigEndian,
+	"varint":  cast.Varint,
+	"minimal": cast.MinimalBigEndian,
+}
+
+//nolint:gochecknoglobals
+var roundingRegistry = map[string]big.RoundingMode{
+	"half_even": big.ToNearestEven,
+	"half_up":   big.ToNearestAway,
+	"down":      big.ToZero,
+	"up":        big.AwayFromZero,
+	"floor":     big.ToNegativeInf,
+	"ceiling":   big.ToPositiveInf,
+}
+
+//nolint:gochecknoglobals
+var extraColumnsRegistry = map[string]ExtraColumnsPolicy{
+	"keep":  ExtraColumnsKeep,
+	"drop":  ExtraColumnsDrop,
+	"error": ExtraColumnsError,
+}
+
+// ReadRowDefinition reads a YAML row definition.
+func ReadRowDefinition(r io.Reader) (*RowDefinition, error) {
+	def := &RowDefinition{
+		ExtraColumns:   "",
+		Timezone:       "",
+		AssumeTimezone: "",
+		OmitNull:       nil,
+		EmptyAsNull:    nil,
+		NullIf:         nil,
+		NullAs:         nil,
+		Columns:        []ColumnDefinition{},
+	}
+
+	if err := yaml.NewDecoder(r).Decode(def); err != nil && err != io.EOF { //nolint:errorlint
+		return nil, fmt.Errorf("%w", err)
+	}
+
+	return def, nil
+}
+
+// LoadRowDefinition reads a YAML row definition file (e.g. : row.yml).
+func LoadRowDefinition(filename string) (*RowDefinition, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("%w", err)
+	}
+	defer file.Close()
+
+	return ReadRowDefinition(file)
+}
+
+// ParseInlineDefinition parses an inline row definition, e.g. : {"name":"format(type):format=default"}.
+// Arrays are declared with a single element, e.g. : {"name":["format"]} or {"name":[{"sub":"format"}]}.
+func ParseInlineDefinition(input string) (*RowDefinition, error) {
+	row := NewRow()
+
+	if err := json.Unmarshal([]byte(input), row); err != nil {
+		return nil, fmt.Errorf("%w", err)
+	}
+
+	columns, err := inlineColumns(row)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RowDefinition{
+		ExtraColumns:   "",
+		Timezone:       "",
+		AssumeTimezone: "",
+		OmitNull:       nil,
+		EmptyAsNull:    nil,
+		NullIf:         nil,
+		NullAs:         nil,
+		Columns:        columns,
+	}, nil
+}
+
+func inlineColumns(row Row) ([]ColumnDefinition, error) {
+	columns := []ColumnDefinition{}
+
+	iter := row.Iter()
+	for colname, coldef, ok := iter(); ok; colname, coldef, ok = iter() {
+		column := ColumnDefinition{Name: colname} //nolint:exhaustivestruct
+
+		if elements, isArray := coldef.([]interface{}); isArray {
+			if len(elements) != 1 {
+				return nil, fmt.Errorf("%w: array column %s must define exactly one element", ErrInvalidTemplate, colname)
+			}
+
+			column.Array = true
+			coldef = elements[0]
+		}
+
+		switch def := coldef.(type) {
+		case string:
+			parts := splitDescriptor(def, '=', 2)            //nolint:gomnd
+			descriptors := splitDescriptor(parts[0], ':', 2) //nolint:gomnd
+			column.Input = descriptors[0]
+			column.Output = descriptors[len(descriptors)-1]
+
+			if len(parts) > 1 && !column.Array {
+				column.Default = parts[1]
+			}
+		case Row:
+			sub, err := inlineColumns(def)
+			if err != nil {
+				return nil, err
+			}
+
+			column.Columns = sub
+		default:
+			continue
+		}
+
+		columns = append(columns, column)
+	}
+
+	return columns, nil
+}
+
+// Templates returns the input and the output templates declared by the row definition.
+func (d *RowDefinition) Templates() (Template, Template, error) {
+	policy, err := ParseExtraColumnsPolicy(d.ExtraColumns)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	zones, err := timezones{location: nil, assume: nil}.with(d.Timezone, d.AssumeTimezone)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	nulls := nullPolicy{omit: false, empty: false, sentinels: nil, replacement: nil}.
+		with(d.OmitNull, d.EmptyAsNull, d.NullIf, d.NullAs)
+
+	return parseColumns(NewTemplate(), NewTemplate(), d.Columns, policy, zones, nulls)
+}
+
+// timezones are the timezone settings of a row definition, inherited by the columns and the sub-rows.
+type timezones struct {
+	location *time.Location
+	assume   *time.Location
+}
+
+// with returns the settings overridden by the non empty timezone names.
+func (z timezones) with(tz string, assume string) (timezones, error) {
+	var err error
+
+	if len(tz) > 0 {
+		if z.location, err = time.LoadLocation(tz); err != nil {
+			return z, fmt.Errorf("%w: unknown timezone %q: %v", ErrInvalidTemplate, tz, err)
+		}
+	}
+
+	if len(assume) > 0 {
+		if z.assume, err = time.LoadLocation(assume); err != nil {
+			return z, fmt.Errorf("%w: unknown timezone %q: %v", ErrInvalidTemplate, assume, err)
+		}
+	}
+
+	return z, nil
+}
+
+// options returns the value options of the settings, only Date and DateTime formats are concerned.
+func (z timezones) options(format Format) []ValueOption {
+	result := []ValueOption{}
+
+	if format != Date && format != DateTime {
+		return result
+	}
+
+	if z.location != nil {
+		result = append(result, Timezone(z.location))
+	}
+
+	if z.assume != nil {
+		result = append(result, AssumeTimezone(z.assume))
+	}
+
+	return result
+}
+
+// nullPolicy is the null policy of a row definition, inherited by the columns and the sub-rows.
+type nullPolicy struct {
+	omit        bool
+	empty       bool
+	sentinels   []interface{}
+	replacement interface{}
+}
+
+// with returns the policy overridden by the non nil settings.
+func (p nullPolicy) with(omit *bool, empty *bool, sentinels []interface{}, replacement interface{}) nullPolicy {
+	if omit != nil {
+		p.omit = *omit
+	}
+
+	if empty != nil {
+		p.empty = *empty
+	}
+
+	if sentinels != nil {
+		p.sentinels = sentinels
+	}
+
+	if replacement != nil {
+		p.replacement = replacement
+	}
+
+	return p
+}
+
+// options returns the value options of the policy.
+func (p nullPolicy) options() []ValueOption {
+	result := []ValueOption{}
+
+	if p.omit {
+		result = append(result, OmitNull())
+	}
+
+	if p.empty {
+		result = append(result, EmptyAsNull())
+	}
+
+	if len(p.sentinels) > 0 {
+		result = append(result, NullIf(p.sentinels...))
+	}
+
+	if p.replacement != nil {
+		result = append(result, ReplaceNull(p.replacement))
+	}
+
+	return result
+}
+
+// ParseExtraColumnsPolicy parses an extra columns policy : keep, drop or error (empty means keep).
+func ParseExtraColumnsPolicy(def string) (ExtraColumnsPolicy, error) {
+	if len(def) == 0 {
+		return ExtraColumnsKeep, nil
+	}
+
+	policy, ok := extraColumnsRegistry[def]
+	if !ok {
+		return ExtraColumnsKeep, fmt.Errorf("%w: unknown extra columns policy %q (expected keep, drop or error)",
+			ErrInvalidTemplate, def)
+	}
+
+	return policy, nil
+}
+
+// ParseDescriptor parses a "<FORMAT>[<PARAMS>](<TYPE>)" descriptor, params and type are optional (e.g. : "numeric",
+// "numeric(int64)", "datetime[02/01/2006|2006-01-02]"). Decimals accept a precision and a scale before the type, and a
+// rounding mode as param (e.g. : "decimal(18,2)", "decimal(18,2)[half_up](*big.Rat)"), durations accept a notation as
+// param (e.g. : "duration[iso8601]"), binaries accept an encoding as param and a byte layout after the type (e.g. :
+// "binary[hex](int64,be)"). An empty descriptor is parsed as Auto, unknown formats, params and byte layouts return
+// ErrInvalidFormat and unknown types return ErrInvalidRawType.
+//
+//nolint:cyclop,funlen
+func ParseDescriptor(def string) (Format, RawType, []ValueOption, error) {
+	var (
+		rawtype RawType
+		opts    []ValueOption
+	)
+
+	if len(def) == 0 {
+		return Auto, nil, nil, nil
+	}
+
+	// "<FORMAT>[<PARAMS>](<TYPE>)" or "<FORMAT>(<TYPE>)[<PARAMS>]", decimals have an optional "(<PRECISION>,<SCALE>)"
+	r := regexp.MustCompile(
+		`^([^\(\[]+)(?:\[([^\]]*)\])?(?:\(([^\)]+)\))?(?:\[([^\]]*)\])?(?:\(([^\)]+)\))?(?:\[([^\]]*)\])?$`)
+	submatches := r.FindStringSubmatch(def)
+
+	if submatches == nil {
+		return Auto, nil, nil, fmt.Errorf("%w: %q", ErrInvalidFormat, def)
+	}
+
+	format, ok := formatRegistry[submatches[1]]
+	if !ok {
+		return Auto, nil, nil, fmt.Errorf("%w: unknown format %q", ErrInvalidFormat, submatches[1])
+	}
+
+	//nolint:gomnd
+	typename, params := submatches[3], submatches[2]+submatches[4]+submatches[6]
+
+	if format == Decimal {
+		if digits := regexp.MustCompile(`^\s*([0-9]+)\s*(?:,\s*([0-9]+)\s*)?$`).FindStringSubmatch(typename); digits != nil {
+			precision, _ := strconv.Atoi(digits[1])
+			scale, _ := strconv.Atoi(digits[2])
+			opts = append(opts, Precision(precision, scale))
+			typename = submatches[5]
+		}
+	} else if len(submatches[5]) > 0 {
+		return Auto, nil, nil, fmt.Errorf("%w: %q", ErrInvalidFormat, def)
+	}
+
+	// binaries accept a byte layout after the type, e.g. : "binary(int64,be)"
+	if parts := strings.SplitN(typename, ",", 2); format == Binary && len(parts) == 2 {
+		layout, ok := byteLayoutRegistry[strings.TrimSpace(parts[1])]
+		if !ok {
+			return Auto, nil, nil, fmt.Errorf("%w: unknown byte layout %q", ErrInvalidFormat, parts[1])
+		}
+
+		opts = append(opts, ByteLayout(layout))
+		typename = strings.TrimSpace(parts[0])
+	}
+
+	if len(typename) > 0 {
+		if rawtype, ok = typeRegistry[typename]; !ok {
+			return Auto, nil, nil, fmt.Errorf("%w: unknown type %q", ErrInvalidRawType, typename)
+		}
+	}
+
+	if len(params) > 0 {
+		opt, ok := paramOption(format, params)
+		if !ok {
+			return Auto, nil, nil, fmt.Errorf("%w: unknown param %q for format %s", ErrInvalidFormat, params,
+				formatName(format))
+		}
+
+		opts = append(opts, opt)
+	}
+
+	return format, rawtype, opts, nil
+}
+
+// paramOption returns the option set by the param of a descriptor : layouts of dates, rounding mode of decimals,
+// notation of durations or encoding of binaries.
+func paramOption(format Format, params string) (ValueOption, bool) {
+	switch format { //nolint:exhaustive
+	case Date, DateTime:
+		return Layouts(strings.Split(params, "|")...), true
+	case Decimal:
+		mode, ok := roundingRegistry[params]
+
+		return Rounding(mode), ok
+	case Duration:
+		notation, ok := notationRegistry[params]
+
+		return Notation(notation), ok
+	case Binary:
+		encoding, ok := encodingRegistry[params]
+
+		return Encoding(encoding), ok
+	default:
+		return nil, false
+	}
+}
+
+// Descriptor returns the "<FORMAT>[<PARAMS>](<TYPE>)" descriptor of a format, a rawtype and options.
+//
+//nolint:cyclop
+func Descriptor(format Format, rawtype RawType, opts ...ValueOption) string {
+	result := formatName(format)
+
+	o := newOptions(opts)
+
+	if o != nil && o.digits != nil {
+		result += fmt.Sprintf("(%d,%d)", o.digits.precision, o.digits.scale)
+	}
+
+	if o != nil && len(o.layouts) > 0 {
+		result += "[" + strings.Join(o.layouts, "|") + "]"
+	}
+
+	if o != nil && o.notation != DurationGo {
+		for name, notation := range notationRegistry {
+			if notation == o.notation {
+				result += "[" + name + "]"
+			}
+		}
+	}
+
+	if o != nil && o.encoding != Base64 {
+		for name, encoding := range encodingRegistry {
+			if encoding == o.encoding {
+				result += "[" + name + "]"
+			}
+		}
+	}
+
+	if o != nil && o.rounding != big.ToNearestEven {
+		for name, mode := range roundingRegistry {
+			if mode == o.rounding {
+				result += "[" + name + "]"
+			}
+		}
+	}
+
+	if rawtype != nil {
+		typename := rawTypeName(rawtype)
+
+		if o != nil && o.bytes != cast.LittleEndian {
+			typename += "," + byteLayoutName(o.bytes)
+		}
+
+		result += "(" + typename + ")"
+	}
+
+	return result
+}
+
+// byteLayoutName returns the first name of the byte layout in alphabetical order.
+func byteLayoutName(layout cast.ByteLayout) string {
+	result := ""
+
+	for name, l := range byteLayoutRegistry {
+		if l == layout && (result == "" || name < result) {
+			result = name
+		}
+	}
+
+	return result
+}
+
+// splitDescriptor splits the descriptor in at most n parts around the separators that are not between brackets.
+func splitDescriptor(def string, sep rune, n int) []string {
+	result := []string{}
+	depth, start := 0, 0
+
+	for i, r := range def {
+		switch {
+		case r == '[':
+			depth++
+		case r == ']' && depth > 0:
+			depth--
+		case r == sep && depth == 0 && len(result) < n-1:
+			result = append(result, def[start:i])
+			start = i + 1
+		}
+	}
+
+	return append(result, def[start:])
+}
+
+// parseDefault parses a default value definition : now(), sequence(), sequence(<START>) or a literal value.
+func parseDefault(def interface{}) DefaultValue {
+	str, ok := def.(string)
+	if !ok {
+		return Literal(def)
+	}
+
+	r := regexp.MustCompile(`^sequence\((-?[0-9]*)\)$`)
+
+	switch submatches := r.FindStringSubmatch(str); {
+	case str == "now()":
+		return Now()
+	case submatches != nil && submatches[1] == "":
+		return Sequence(1)
+	case submatches != nil:
+		start, _ := strconv.ParseInt(submatches[1], 10, 64)
+
+		return Sequence(start)
+	default:
+		return Literal(str)
+	}
+}
+
+// parseConstraints returns the constraints declared on the column definition.
+func parseConstraints(column ColumnDefinition) ([]Constraint, error) {
+	constraints := []Constraint{}
+
+	if column.Required {
+		constraints = append(constraints, Required())
+	}
+
+	if column.Nullable != nil && !*column.Nullable {
+		constraints = append(constraints, NotNull())
+	}
+
+	if len(column.Enum) > 0 {
+		constraints = append(constraints, Enum(column.Enum...))
+	}
+
+	if len(column.Pattern) > 0 {
+		re, err := regexp.Compile(column.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("%w: column %s: %v", ErrInvalidTemplate, column.Name, err)
+		}
+
+		constraints = append(constraints, Pattern(re))
+	}
+
+	if column.Min != nil {
+		constraints = append(constraints, Min(column.Min))
+	}
+
+	if column.Max != nil {
+		constraints = append(constraints, Max(column.Max))
+	}
+
+	if column.MinLength != nil {
+		constraints = append(constraints, MinLength(*column.MinLength))
+	}
+
+	if column.MaxLength != nil {
+		constraints = append(constraints, MaxLength(*column.MaxLength))
+	}
+
+	return constraints, nil
+}
+
+//nolint:cyclop
+func parseColumns(ti Template, to Template, columns []ColumnDefinition,
+	policy ExtraColumnsPolicy, zones timezones, nulls nullPolicy) (Template, Template, error) {
+	ti.WithExtraColumns(policy)
+	to.WithExtraColumns(policy)
+
+	for _, column := range columns {
+		subzones, err := zones.with(column.Timezone, column.AssumeTimezone)
+		if err != nil {
+			return ti, to, err
+		}
+
+		iformat, irawtype, iopts, err := ParseDescriptor(column.Input)
+		if err != nil {
+			return ti, to, fmt.Errorf("%w (column %s)", err, column.Name)
+		}
+
+		oformat, orawtype, oopts, err := ParseDescriptor(column.Output)
+		if err != nil {
+			return ti, to, fmt.Errorf("%w (column %s)", err, column.Name)
+		}
+
+		subnulls := nulls.with(column.OmitNull, column.EmptyAsNull, column.NullIf, column.NullAs)
+
+		iopts = append(append(iopts, subzones.options(iformat)...), subnulls.options()...)
+		oopts = append(append(oopts, subzones.options(oformat)...), subnulls.options()...)
+
+		if column.Array {
+			ti.WithArray(column.Name, iformat, irawtype, iopts...)
+			to.WithArray(column.Name, oformat, orawtype, oopts...)
+		} else {
+			ti.With(column.Name, iformat, irawtype, iopts...)
+			to.With(column.Name, oformat, orawtype, oopts...)
+		}
+
+		// constraints are checked on input only
+		constraints, err := parseConstraints(column)
+		if err != nil {
+			return ti, to, err
+		}
+
+		if len(constraints) > 0 {
+			ti.WithConstraints(column.Name, constraints...)
+		}
+
+		// defaults are applied on input only
+		if column.Default != nil {
+			ti.WithDefault(column.Name, parseDefault(column.Default))
+		}
+
+		if len(column.Columns) > 0 {
+			subpolicy := policy
+
+			if len(column.ExtraColumns) > 0 {
+				if subpolicy, err = ParseExtraColumnsPolicy(column.ExtraColumns); err != nil {
+					return ti, to, err
+				}
+			}
+
+			rowti, rowto, err := parseColumns(NewTemplate(), NewTemplate(), column.Columns, subpolicy, subzones,
+				subnulls)
+			if err != nil {
+				return ti, to, err
+			}
+
+			ti = withSubRow(ti, column, iformat, irawtype, iopts, rowti)
+			to = withSubRow(to, column, oformat, orawtype, oopts, rowto)
+		}
+	}
+
+	return ti, to, nil
+}
+
+// withSubRow declares the sub-row of a column, JSON columns hold the sub-row embedded in a string.
+func withSubRow(t Template, column ColumnDefinition, format Format, rawtype RawType, opts []ValueOption,
+	sub Template) Template {
+	switch {
+	case format == JSON && column.Array:
+		return t.WithArray(column.Name, format, rawtype, append(opts, Embedded(sub))...)
+	case format == JSON:
+		return t.With(column.Name, format, rawtype, append(opts, Embedded(sub))...)
+	case column.Array:
+		return t.WithArrayOfRows(column.Name, sub)
+	default:
+		return t.WithRow(column.Name, sub)
+	}
+}
+
+// NewRowDefinition returns the definition of the template, the template is declared as input. Custom constraints can't
+// be written in a row definition and are ignored.
+func NewRowDefinition(t Template) *RowDefinition {
+	policy := extraColumnsOf(t)
+
+	return &RowDefinition{
+		ExtraColumns:   extraColumnsName(policy, ExtraColumnsKeep),
+		Timezone:       "",
+		AssumeTimezone: "",
+		OmitNull:       nil,
+		EmptyAsNull:    nil,
+		NullIf:         nil,
+		NullAs:         nil,
+		Columns:        columnDefinitions(t, policy),
+	}
+}
+
+//nolint:cyclop
+func columnDefinitions(t Template, policy ExtraColumnsPolicy) []ColumnDefinition {
+	result := []ColumnDefinition{}
+
+	for _, column := range t.Columns() {
+		def := ColumnDefinition{Name: column.Name, Array: column.Array} //nolint:exhaustivestruct
+
+		switch {
+		case column.Template != nil:
+			subpolicy := extraColumnsOf(column.Template)
+			def.Columns = columnDefinitions(column.Template, subpolicy)
+			def.ExtraColumns = extraColumnsName(subpolicy, policy)
+
+			if column.Format == JSON {
+				def.Input = Descriptor(column.Format, column.RawType, column.Options...)
+			}
+		case column.Format != Auto || column.RawType != nil:
+			def.Input = Descriptor(column.Format, column.RawType, column.Options...)
+		}
+
+		if o := newOptions(column.Options); o != nil {
+			def.Timezone = locationName(o.location)
+			def.AssumeTimezone = locationName(o.assume)
+			def.OmitNull = trueOrNil(o.omitNull)
+			def.EmptyAsNull = trueOrNil(o.empty)
+			def.NullIf = o.nulls
+			def.NullAs = o.nullAs
+		}
+
+		for _, constraint := range column.Constraints {
+			switch c := constraint.(type) {
+			case required:
+				def.Required = true
+			case notNull:
+				nullable := false
+				def.Nullable = &nullable
+			case enum:
+				def.Enum = c.values
+			case pattern:
+				def.Pattern = c.re.String()
+			case bound:
+				if c.min {
+					def.Min = c.limit
+				} else {
+					def.Max = c.limit
+				}
+			case length:
+				limit := c.limit
+				if c.min {
+					def.MinLength = &limit
+				} else {
+					def.MaxLength = &limit
+				}
+			}
+		}
+
+		if column.Default != nil {
+			def.Default = column.Default.Definition()
+		}
+
+		result = append(result, def)
+	}
+
+	return result
+}
+
+// trueOrNil returns a pointer to true, or nil if the flag is false.
+func trueOrNil(flag bool) *bool {
+	if !flag {
+		return nil
+	}
+
+	return &flag
+}
+
+// locationName returns the name of the timezone, or an empty string if the timezone is nil.
+func locationName(loc *time.Location) string {
+	if loc == nil {
+		return ""
+	}
+
+	return loc.String()
+}
+
+func extraColumnsOf(t Template) ExtraColumnsPolicy {
+	if typed, ok := t.(*template); ok {
+		if r, ok := typed.empty.(*row); ok && r.schema != nil {
+			return r.schema.extra
+		}
+	}
+
+	return ExtraColumnsKeep
+}
+
+// extraColumnsName returns the name of the policy, or an empty string if the policy is inherited.
+func extraColumnsName(policy ExtraColumnsPolicy, inherited ExtraColumnsPolicy) string {
+	if policy == inherited {
+		return ""
+	}
+
+	for name, p := range extraColumnsRegistry {
+		if p == policy {
+			return name
+		}
+	}
+
+	return ""
+}
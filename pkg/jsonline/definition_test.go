@@ -0,0 +1,291 @@
+// Copyright (C) 2022 CGI France
+//
+// This file is part of the jsonline library.
+//
+// The jsonline library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The jsonline library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with the jsonline library.  If not, see <http://www.gnu.org/licenses/>.
+
+package jsonline_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/cgi-fr/jsonline/pkg/jsonline"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+const rowDefinition = `extra-columns: drop
+columns:
+  - name: id
+    input: numeric(int64)
+    default: sequence(1)
+  - name: created
+    input: datetime
+    output: timestamp
+  - name: status
+    required: true
+    nullable: false
+    enum:
+      - new
+      - done
+  - name: lines
+    array: true
+    maxLength: 10
+    extra-columns: error
+    columns:
+      - name: qty
+        input: numeric(int64)
+        min: 1
+`
+
+func TestReadRowDefinition(t *testing.T) {
+	def, err := jsonline.ReadRowDefinition(strings.NewReader(rowDefinition))
+	assert.NoError(t, err)
+	assert.Equal(t, "drop", def.ExtraColumns)
+	assert.Len(t, def.Columns, 4)
+
+	ti, to, err := def.Templates()
+	assert.NoError(t, err)
+
+	row, err := ti.CreateRow([]byte(`{"created":"2021-09-24T21:21:21Z","status":"new","lines":[{"qty":2}],"extra":1}`))
+	assert.NoError(t, err)
+	assert.Equal(t, `{"id":1,"created":"2021-09-24T21:21:21Z","status":"new","lines":[{"qty":2}]}`, row.String())
+
+	row, err = to.CreateRow(row)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"id":1,"created":1632518481,"status":"new","lines":[{"qty":2}]}`, row.String())
+
+	_, err = ti.CreateRow([]byte(`{"id":2,"status":"new","lines":[{"qty":2,"extra":1}]}`))
+	assert.ErrorIs(t, err, jsonline.ErrExtraColumn)
+}
+
+func TestParseInlineDefinition(t *testing.T) {
+	def, err := jsonline.ParseInlineDefinition(
+		`{"id":"numeric(int64)=sequence(10)","created":"datetime:timestamp","tags":["string"],"sub":{"a":"string"}}`)
+	assert.NoError(t, err)
+
+	nullable := (*bool)(nil)
+	assert.Equal(t, []jsonline.ColumnDefinition{
+		{Name: "id", Input: "numeric(int64)", Output: "numeric(int64)", Default: "sequence(10)", Nullable: nullable},
+		{Name: "created", Input: "datetime", Output: "timestamp"},
+		{Name: "tags", Input: "string", Output: "string", Array: true},
+		{Name: "sub", Columns: []jsonline.ColumnDefinition{{Name: "a", Input: "string", Output: "string"}}},
+	}, def.Columns)
+
+	_, err = jsonline.ParseInlineDefinition(`{"tags":["string","string"]}`)
+	assert.ErrorIs(t, err, jsonline.ErrInvalidTemplate)
+}
+
+func TestTemplateColumns(t *testing.T) {
+	template := jsonline.NewTemplate().
+		WithMappedNumeric("id", int64(0)).
+		WithRow("address", jsonline.NewTemplate().WithString("city")).
+		WithArrayOfRows("lines", jsonline.NewTemplate().WithNumeric("qty")).
+		WithArray("tags", jsonline.String, nil).
+		WithConstraints("id", jsonline.Required()).
+		WithDefault("id", jsonline.Sequence(5))
+
+	columns := template.Columns()
+	assert.Len(t, columns, 4)
+	assert.Equal(t, "id", columns[0].Name)
+	assert.Equal(t, jsonline.Numeric, columns[0].Format)
+	assert.Equal(t, int64(0), columns[0].RawType)
+	assert.Len(t, columns[0].Constraints, 1)
+	assert.Equal(t, "sequence(5)", columns[0].Default.Definition())
+	assert.Equal(t, "city", columns[1].Template.Columns()[0].Name)
+	assert.True(t, columns[2].Array)
+	assert.Equal(t, "qty", columns[2].Template.Columns()[0].Name)
+	assert.True(t, columns[3].Array)
+	assert.Nil(t, columns[3].Template)
+	assert.Equal(t, jsonline.String, columns[3].Format)
+
+	column, ok := template.Column("tags")
+	assert.True(t, ok)
+	assert.Equal(t, "tags", column.Name)
+
+	_, ok = template.Column("unknown")
+	assert.False(t, ok)
+}
+
+func TestTemplateMarshal(t *testing.T) {
+	def, err := jsonline.ReadRowDefinition(strings.NewReader(rowDefinition))
+	assert.NoError(t, err)
+
+	ti, _, err := def.Templates()
+	assert.NoError(t, err)
+
+	var buffer bytes.Buffer
+
+	encoder := yaml.NewEncoder(&buffer)
+	encoder.SetIndent(2)
+	assert.NoError(t, encoder.Encode(ti))
+
+	result := buffer.Bytes()
+
+	// output formats are not part of the input template
+	assert.Equal(t, strings.Replace(rowDefinition, "    output: timestamp\n", "", 1), string(result))
+
+	template := jsonline.NewTemplate()
+	assert.NoError(t, yaml.Unmarshal(result, template))
+
+	jsonResult, err := json.Marshal(template)
+	assert.NoError(t, err)
+
+	template = jsonline.NewTemplate()
+	assert.NoError(t, json.Unmarshal(jsonResult, template))
+
+	row, err := template.CreateRow(map[string]interface{}{"status": "done"})
+	assert.NoError(t, err)
+	assert.Equal(t, `{"id":1,"created":null,"status":"done","lines":null}`, row.String())
+}
+
+func TestParseInlineDefinitionWithLayouts(t *testing.T) {
+	def, err := jsonline.ParseInlineDefinition(`{"d":"datetime[02/01/2006 15:04]:datetime=now()"}`)
+	assert.NoError(t, err)
+	assert.Equal(t, "datetime[02/01/2006 15:04]", def.Columns[0].Input)
+	assert.Equal(t, "datetime", def.Columns[0].Output)
+	assert.Equal(t, "now()", def.Columns[0].Default)
+}
+
+func TestRowDefinitionInvalidDescriptors(t *testing.T) {
+	testdatas := []struct {
+		descriptor string
+		expected   error
+	}{
+		{"numerc", jsonline.ErrInvalidFormat},
+		{"numeric(int65)", jsonline.ErrInvalidRawType},
+		{"binary(int64,middle)", jsonline.ErrInvalidFormat},
+		{"duration[weeks]", jsonline.ErrInvalidFormat},
+		{"string[upper]", jsonline.ErrInvalidFormat},
+		{"string(int64)(int64)", jsonline.ErrInvalidFormat},
+	}
+
+	for _, td := range testdatas {
+		t.Run(td.descriptor, func(t *testing.T) {
+			_, _, _, err := jsonline.ParseDescriptor(td.descriptor)
+			assert.ErrorIs(t, err, td.expected)
+
+			def, err := jsonline.ParseInlineDefinition(`{"a":"` + td.descriptor + `"}`)
+			assert.NoError(t, err)
+
+			_, _, err = def.Templates()
+			assert.ErrorIs(t, err, td.expected)
+		})
+	}
+
+	format, rawtype, opts, err := jsonline.ParseDescriptor("")
+	assert.NoError(t, err)
+	assert.Equal(t, jsonline.Auto, format)
+	assert.Nil(t, rawtype)
+	assert.Empty(t, opts)
+}
+
+func TestRowDefinitionTimezones(t *testing.T) {
+	def, err := jsonline.ReadRowDefinition(strings.NewReader(`
+tz: UTC
+columns:
+  - name: created
+    input: datetime
+  - name: local
+    input: datetime[2006-01-02 15:04]
+    tz: Europe/Paris
+    assume-tz: America/New_York
+  - name: address
+    tz: Europe/Paris
+    columns:
+      - name: since
+        input: datetime
+`))
+	assert.NoError(t, err)
+
+	ti, _, err := def.Templates()
+	assert.NoError(t, err)
+
+	row, err := ti.CreateRow([]byte(
+		`{"created":"2021-09-24T21:21:54+02:00","local":"2021-09-24 21:21","address":{"since":"2021-09-24T19:21:54Z"}}`))
+	assert.NoError(t, err)
+	assert.Equal(t, `{"created":"2021-09-24T19:21:54Z","local":"2021-09-25 03:21",`+
+		`"address":{"since":"2021-09-24T21:21:54+02:00"}}`, row.String())
+
+	columns := jsonline.NewRowDefinition(ti).Columns
+	assert.Equal(t, "UTC", columns[0].Timezone)
+	assert.Equal(t, "Europe/Paris", columns[1].Timezone)
+	assert.Equal(t, "America/New_York", columns[1].AssumeTimezone)
+	assert.Equal(t, "Europe/Paris", columns[2].Columns[0].Timezone)
+
+	def.Timezone = "Mars/Base"
+	_, _, err = def.Templates()
+	assert.ErrorIs(t, err, jsonline.ErrInvalidTemplate)
+}
+
+func TestRowDefinitionEmbeddedJSON(t *testing.T) {
+	def, err := jsonline.ReadRowDefinition(strings.NewReader(`
+columns:
+  - name: payload
+    input: json
+    columns:
+      - name: amount
+        input: numeric(int64)
+`))
+	assert.NoError(t, err)
+
+	ti, to, err := def.Templates()
+	assert.NoError(t, err)
+
+	row, err := ti.CreateRow([]byte(`{"payload":"{\"amount\":\"12\"}"}`))
+	assert.NoError(t, err)
+
+	row, err = to.CreateRow(row)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"payload":{"amount":12}}`, row.String())
+
+	columns := jsonline.NewRowDefinition(ti).Columns
+	assert.Equal(t, "json", columns[0].Input)
+	assert.Equal(t, "numeric(int64)", columns[0].Columns[0].Input)
+}
+
+func TestRowDefinitionNullPolicies(t *testing.T) {
+	def, err := jsonline.ReadRowDefinition(strings.NewReader(`
+omit-null: true
+columns:
+  - name: id
+  - name: name
+    empty-as-null: true
+    null-if: ["N/A"]
+  - name: comment
+    omit-null: false
+  - name: address
+    columns:
+      - name: city
+        null-as: "unknown"
+`))
+	assert.NoError(t, err)
+
+	_, to, err := def.Templates()
+	assert.NoError(t, err)
+
+	row, err := to.CreateRow([]byte(`{"id":null,"name":"","comment":null,"address":{"city":null}}`))
+	assert.NoError(t, err)
+	assert.Equal(t, `{"comment":null,"address":{"city":"unknown"}}`, row.String())
+
+	columns := jsonline.NewRowDefinition(to).Columns
+	assert.True(t, *columns[0].OmitNull)
+	assert.True(t, *columns[1].EmptyAsNull)
+	assert.Equal(t, []interface{}{"N/A"}, columns[1].NullIf)
+	assert.Nil(t, columns[2].OmitNull)
+	assert.Equal(t, "unknown", columns[3].Columns[0].NullAs)
+}
@@ -0,0 +1,130 @@
+// Copyright (C) 2022 CGI France
+//
+// This file is part of the jsonline library.
+//
+// The jsonline library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The jsonline library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with the jsonline library.  If not, see <http://www.gnu.org/licenses/>.
+//
+// Linking this library statically or dynamically with other modules is
+// making a combined work based on this library.  Thus, the terms and
+// conditions of the GNU General Public License cover the whole
+// combination.
+//
+// As a special exception, the copyright holders of this library give you
+// permission to link this library with independent modules to produce an
+// executable, regardless of the license terms of these independent
+// modules, and to copy and distribute the resulting executable under
+// terms of your choice, provided that you also meet, for each linked
+// independent module, the terms and conditions of the license of that
+// module.  An independent module is a module which is not derived from
+// or based on this library.  If you modify this library, you may extend
+// this exception to your version of the library, but you are not
+// obligated to do so.  If you do not wish to do so, delete this
+// exception statement from your version.
+
+package jsonline
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/cgi-fr/jsonline/pkg/cast"
+)
+
+// decodeEmbedded returns the document held by a JSON value, strings are parsed (objects become rows to preserve the
+// order of keys) and other values are already decoded documents. The document is created by the embedded template of
+// the options if any, strings are then imported by the template so the formats of its columns are enforced.
+func decodeEmbedded(val interface{}, opts *valueOptions) (interface{}, error) {
+	doc := val
+
+	switch typed := val.(type) {
+	case string:
+		var err error
+		if doc, err = parseEmbedded([]byte(typed)); err != nil {
+			return nil, err
+		}
+
+		if _, isObject := doc.(Row); isObject && opts != nil && opts.embedded != nil {
+			return opts.embedded.CreateRow([]byte(typed))
+		}
+	case []byte:
+		return decodeEmbedded(string(typed), opts)
+	case json.RawMessage:
+		return decodeEmbedded(string(typed), opts)
+	}
+
+	if opts == nil || opts.embedded == nil || doc == nil {
+		return doc, nil
+	}
+
+	switch doc.(type) {
+	case Row, map[string]interface{}:
+		return opts.embedded.CreateRow(doc)
+	default:
+		return nil, fmt.Errorf("%w: %T is not a JSON object", ErrNotARow, doc)
+	}
+}
+
+// parseEmbedded parses a JSON document, numbers are decoded as json.Number.
+func parseEmbedded(data []byte) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	t, err := dec.Token()
+	if err != nil {
+		return nil, fmt.Errorf("%w", err)
+	}
+
+	doc, err := handledelim(t, dec)
+	if err != nil {
+		return nil, err
+	}
+
+	if t, err = dec.Token(); err != io.EOF { //nolint:errorlint
+		return nil, fmt.Errorf("expect end of JSON document but got more token: %T: %v", t, t)
+	}
+
+	return doc, nil
+}
+
+// encodeEmbedded returns the compact JSON representation of the document.
+func encodeEmbedded(doc interface{}) (string, error) {
+	b, err := json.Marshal(doc)
+	if err != nil {
+		return "", fmt.Errorf("%w", err)
+	}
+
+	return string(b), nil
+}
+
+// embeddedRaw converts the document to the rawtype, string and []byte rawtypes hold the JSON representation.
+func embeddedRaw(doc interface{}, targetType RawType) (interface{}, error) {
+	switch targetType.(type) {
+	case nil:
+		return doc, nil
+	default:
+		str, err := encodeEmbedded(doc)
+		if err != nil {
+			return nil, err
+		}
+
+		result, err := cast.To(targetType, str)
+		if err != nil {
+			return nil, fmt.Errorf("%w", err)
+		}
+
+		return result, nil
+	}
+}
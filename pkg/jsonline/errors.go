@@ -40,7 +40,22 @@
 
 var (
 	ErrUnsupportedFormat     = errors.New("unsupported format")
+	ErrInvalidFormat         = errors.New("invalid format")
+	ErrInvalidRawType        = errors.New("invalid rawtype")
 	ErrUnsupportedImportType = errors.New("can't import type")
 	ErrUnsupportedExportType = errors.New("can't export type")
 	ErrPathNotFound          = errors.New("path not found")
+	ErrNotARow               = errors.New("value is not a row")
+	ErrInvalidPath           = errors.New("invalid path")
+	ErrInvalidTemplate       = errors.New("invalid template")
+	ErrExtraColumn           = errors.New("unexpected column")
+	ErrConstraintViolation   = errors.New("constraint violation")
+	ErrMissingColumn         = errors.New("column is missing")
+	ErrNullValue             = errors.New("value is null")
+	ErrNotAllowed            = errors.New("value is not allowed")
+	ErrPatternMismatch       = errors.New("value does not match pattern")
+	ErrOutOfRange            = errors.New("value is out of range")
+	ErrInvalidLength         = errors.New("invalid length")
+	ErrOutOfPrecision        = errors.New("value exceeds the precision of the decimal")
+	ErrInvalidTag            = errors.New("invalid struct tag")
 )
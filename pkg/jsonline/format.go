@@ -0,0 +1,156 @@
+// Copyright (C) 2022 CGI France
+//
+// This file is part of the jsonline library.
+//
+// The jsonline library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The jsonline library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with the jsonline library.  If not, see <http://www.gnu.org/licenses/>.
+//
+// Linking this library statically or dynamically with other modules is
+// making a combined work based on this library.  Thus, the terms and
+// conditions of the GNU General Public License cover the whole
+// combination.
+//
+// As a special exception, the copyright holders of this library give you
+// permission to link this library with independent modules to produce an
+// executable, regardless of the license terms of these independent
+// modules, and to copy and distribute the resulting executable under
+// terms of your choice, provided that you also meet, for each linked
+// independent module, the terms and conditions of the license of that
+// module.  An independent module is a module which is not derived from
+// or based on this library.  If you modify this library, you may extend
+// this exception to your version of the library, but you are not
+// obligated to do so.  If you do not wish to do so, delete this
+// exception statement from your version.
+
+package jsonline
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+
+	"github.com/cgi-fr/jsonline/pkg/cast"
+)
+
+// ImportFunc converts an imported value to the raw value of a custom format, it returns an error if the value is
+// invalid.
+type ImportFunc func(val interface{}) (interface{}, error)
+
+// ExportFunc converts the raw value of a custom format to its exported representation.
+type ExportFunc func(raw interface{}) (interface{}, error)
+
+type customFormat struct {
+	importFn ImportFunc
+	exportFn ExportFunc
+}
+
+//nolint:gochecknoglobals
+var (
+	customFormats = map[Format]customFormat{}
+	nextFormat    = JSON + 1
+	customTypes   = map[reflect.Type]string{}
+)
+
+// RegisterFormat declares a custom format, the returned format can be used in templates (e.g. : Template.With) and the
+// name in row definitions (e.g. : "siren", "siren(int64)"). Formats must be registered before templates are created
+// (e.g. : in an init function), the registry is not safe for concurrent use.
+func RegisterFormat(name string, importFn ImportFunc, exportFn ExportFunc) (Format, error) {
+	if _, exists := formatRegistry[name]; exists {
+		return Auto, fmt.Errorf("%w: %s is already registered", ErrInvalidFormat, name)
+	}
+
+	if nextFormat == math.MaxInt8 {
+		return Auto, fmt.Errorf("%w: too many formats", ErrInvalidFormat)
+	}
+
+	if importFn == nil || exportFn == nil {
+		return Auto, fmt.Errorf("%w: %s must have import and export functions", ErrInvalidFormat, name)
+	}
+
+	format := nextFormat
+	nextFormat++
+
+	formatRegistry[name] = format
+	customFormats[format] = customFormat{importFn: importFn, exportFn: exportFn}
+
+	return format, nil
+}
+
+// RegisterRawType declares the name of a rawtype in row definitions (e.g. : RegisterRawType("Money", Money{}) allows
+// "numeric(Money)"). The rawtype must be supported by cast.To, either registered with cast.Register or implementing
+// encoding.TextUnmarshaler, json.Unmarshaler or sql.Scanner.
+func RegisterRawType(name string, rawtype RawType) error {
+	if _, exists := typeRegistry[name]; exists {
+		return fmt.Errorf("%w: %s is already registered", ErrInvalidRawType, name)
+	}
+
+	if rawtype == nil {
+		return fmt.Errorf("%w: %s can't be nil", ErrInvalidRawType, name)
+	}
+
+	typeRegistry[name] = rawtype
+	customTypes[reflect.TypeOf(rawtype)] = name
+
+	resetPlans() // structs with fields of this type are no longer mapped the same way
+
+	return nil
+}
+
+// rawTypeName returns the name of the rawtype in row definitions.
+func rawTypeName(rawtype RawType) string {
+	if name, ok := customTypes[reflect.TypeOf(rawtype)]; ok {
+		return name
+	}
+
+	typename := fmt.Sprintf("%T", rawtype)
+	if strings.HasPrefix(typename, "[") && strings.HasSuffix(typename, "]uint8") {
+		typename = strings.TrimSuffix(typename, "uint8") + "byte"
+	}
+
+	return typename
+}
+
+// formatName returns the name of the format in row definitions.
+func formatName(f Format) string {
+	for name, format := range formatRegistry {
+		if format == f {
+			return name
+		}
+	}
+
+	return "auto"
+}
+
+func importFromCustom(val interface{}, targetType RawType, f Format) (interface{}, error) {
+	raw, err := customFormats[f].importFn(val)
+	if err != nil {
+		return nil, fmt.Errorf("%w %T to %s format: %v", ErrUnsupportedImportType, val, formatName(f), err)
+	}
+
+	i, err := cast.To(targetType, raw)
+	if err != nil {
+		return nil, fmt.Errorf("%w %T to %T format: %v", ErrUnsupportedImportType, val, targetType, err)
+	}
+
+	return i, nil
+}
+
+func exportToCustom(val interface{}, f Format) (interface{}, error) {
+	result, err := customFormats[f].exportFn(val)
+	if err != nil {
+		return nil, fmt.Errorf("%w %T to %s format: %v", ErrUnsupportedExportType, val, formatName(f), err)
+	}
+
+	return result, nil
+}
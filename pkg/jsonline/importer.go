@@ -92,6 +92,14 @@ func (i *importer) GetRow() (Row, error) {
 		return nil, fmt.Errorf("%w", err)
 	}
 
+	if err := applyDefaults(row); err != nil {
+		return nil, fmt.Errorf("%w", err)
+	}
+
+	if err := row.Validate(); err != nil {
+		return row, fmt.Errorf("%w", err)
+	}
+
 	return row, nil
 }
 
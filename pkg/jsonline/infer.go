@@ -0,0 +1,310 @@
+// Copyright (C) 2022 CGI France
+//
+// This file is part of the jsonline library.
+//
+// The jsonline library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The jsonline library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with the jsonline library.  If not, see <http://www.gnu.org/licenses/>.
+//
+// Linking this library statically or dynamically with other modules is
+// making a combined work based on this library.  Thus, the terms and
+// conditions of the GNU General Public License cover the whole
+// combination.
+//
+// As a special exception, the copyright holders of this library give you
+// permission to link this library with independent modules to produce an
+// executable, regardless of the license terms of these independent
+// modules, and to copy and distribute the resulting executable under
+// terms of your choice, provided that you also meet, for each linked
+// independent module, the terms and conditions of the license of that
+// module.  An independent module is a module which is not derived from
+// or based on this library.  If you modify this library, you may extend
+// this exception to your version of the library, but you are not
+// obligated to do so.  If you do not wish to do so, delete this
+// exception statement from your version.
+
+package jsonline
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Inference is the result of a template inference on sample rows.
+type Inference struct {
+	Lines   int               // number of rows scanned
+	Columns []*InferredColumn // columns in order of appearance
+
+	index map[string]*InferredColumn
+}
+
+// InferredColumn describes a column observed while inferring a template.
+type InferredColumn struct {
+	Name    string
+	Format  Format
+	RawType RawType
+	Array   bool              // values are arrays, Format and RawType describe the elements
+	Columns []*InferredColumn // columns of sub rows or arrays of rows
+	Count   int               // number of values observed
+	Nulls   int               // number of null values observed
+	Types   []string          // JSON types of non null values : string, number, boolean, object or array
+	Items   []string          // JSON types of non null array elements
+
+	notInteger  bool
+	notDateTime bool
+	notDate     bool
+	notBinary   bool
+	rows        *Inference
+	items       *InferredColumn
+}
+
+// InferTemplate scans at most n rows of the importer (all rows if n <= 0) and returns the most specific template that
+// matches every scanned row, along with the details of the inference.
+func InferTemplate(importer Importer, n int) (Template, *Inference, error) {
+	inference := newInference()
+
+	for (n <= 0 || inference.Lines < n) && importer.Import() {
+		row, err := importer.GetRow()
+		if err != nil {
+			return nil, inference, fmt.Errorf("%w", err)
+		}
+
+		inference.observe(row)
+		inference.Lines++
+	}
+
+	inference.finalize()
+
+	return inference.Template(), inference, nil
+}
+
+func newInference() *Inference {
+	return &Inference{
+		Lines:   0,
+		Columns: []*InferredColumn{},
+		index:   map[string]*InferredColumn{},
+	}
+}
+
+// Template returns a template declaring the inferred columns.
+func (i *Inference) Template() Template {
+	return inferredTemplate(i.Columns)
+}
+
+func inferredTemplate(columns []*InferredColumn) Template {
+	t := NewTemplate()
+
+	for _, column := range columns {
+		switch {
+		case column.Array && len(column.Columns) > 0:
+			t = t.WithArrayOfRows(column.Name, inferredTemplate(column.Columns))
+		case column.Array:
+			t = t.WithArray(column.Name, column.Format, column.RawType)
+		case len(column.Columns) > 0:
+			t = t.WithRow(column.Name, inferredTemplate(column.Columns))
+		default:
+			t = t.With(column.Name, column.Format, column.RawType)
+		}
+	}
+
+	return t
+}
+
+// Mixed returns true if values of different JSON types were observed in the column (or in the elements of the array).
+func (c *InferredColumn) Mixed() bool {
+	return len(c.Types) > 1 || len(c.Items) > 1
+}
+
+// NullRatio returns the ratio of null values observed in the column.
+func (c *InferredColumn) NullRatio() float64 {
+	if c.Count == 0 {
+		return 0
+	}
+
+	return float64(c.Nulls) / float64(c.Count)
+}
+
+// observe records the columns of the row, a new column is placed after the column that precedes it in the row.
+func (i *Inference) observe(r Row) {
+	position := 0
+
+	iter := r.Iter()
+	for key, val, ok := iter(); ok; key, val, ok = iter() {
+		column, exist := i.index[key]
+		if !exist {
+			column = &InferredColumn{Name: key, Format: Auto} //nolint:exhaustivestruct
+			i.index[key] = column
+			i.Columns = append(i.Columns[:position], append([]*InferredColumn{column}, i.Columns[position:]...)...)
+		}
+
+		column.observe(val)
+
+		for idx, c := range i.Columns {
+			if c == column {
+				position = idx + 1
+
+				break
+			}
+		}
+	}
+}
+
+func (i *Inference) finalize() {
+	for _, column := range i.Columns {
+		column.finalize()
+	}
+}
+
+//nolint:cyclop
+func (c *InferredColumn) observe(val interface{}) {
+	c.Count++
+
+	switch v := val.(type) {
+	case nil:
+		c.Nulls++
+	case bool:
+		c.addType("boolean")
+	case json.Number:
+		c.addType("number")
+
+		if _, err := v.Int64(); err != nil {
+			c.notInteger = true
+		}
+	case float64:
+		c.addType("number")
+
+		if v != math.Trunc(v) {
+			c.notInteger = true
+		}
+	case string:
+		c.addType("string")
+		c.observeString(v)
+	case Row:
+		c.addType("object")
+
+		if c.rows == nil {
+			c.rows = newInference()
+		}
+
+		c.rows.observe(v)
+	case []interface{}:
+		c.addType("array")
+
+		if c.items == nil {
+			c.items = &InferredColumn{Name: c.Name, Format: Auto} //nolint:exhaustivestruct
+		}
+
+		for _, item := range v {
+			c.items.observe(item)
+		}
+	default:
+		c.addType(fmt.Sprintf("%T", val))
+	}
+}
+
+func (c *InferredColumn) observeString(str string) {
+	if _, err := time.Parse(time.RFC3339Nano, str); err != nil {
+		c.notDateTime = true
+	}
+
+	if _, err := time.Parse("2006-01-02", str); err != nil {
+		c.notDate = true
+	}
+
+	if !isBase64(str) {
+		c.notBinary = true
+	}
+}
+
+func (c *InferredColumn) addType(typ string) {
+	for _, t := range c.Types {
+		if t == typ {
+			return
+		}
+	}
+
+	c.Types = append(c.Types, typ)
+}
+
+// finalize computes the most specific format of the column, mixed types columns have no specific format.
+func (c *InferredColumn) finalize() { //nolint:cyclop
+	if len(c.Types) != 1 {
+		return
+	}
+
+	switch c.Types[0] {
+	case "boolean":
+		c.Format = Boolean
+	case "number":
+		c.Format = Numeric
+
+		if c.notInteger {
+			c.RawType = float64(0)
+		} else {
+			c.RawType = int64(0)
+		}
+	case "string":
+		switch {
+		case !c.notDateTime:
+			c.Format = DateTime
+		case !c.notDate:
+			c.Format = Date
+		case !c.notBinary:
+			c.Format = Binary
+		default:
+			c.Format = String
+		}
+	case "object":
+		c.rows.finalize()
+		c.Columns = c.rows.Columns
+	case "array":
+		c.Array = true
+		c.items.finalize()
+		c.Format = c.items.Format
+		c.RawType = c.items.RawType
+		c.Columns = c.items.Columns
+		c.Items = c.items.Types
+	}
+}
+
+// isBase64 returns true if the string looks like base64 encoded binary data, plain words that happen to be valid base64
+// are excluded by requiring padding, a non alphanumeric character of the base64 alphabet or a mix of cases and digits.
+func isBase64(str string) bool {
+	const minLength = 4
+
+	if len(str) < minLength || len(str)%4 != 0 {
+		return false
+	}
+
+	if _, err := base64.StdEncoding.DecodeString(str); err != nil {
+		return false
+	}
+
+	if strings.ContainsAny(str, "+/=") {
+		return true
+	}
+
+	var upper, lower, digit bool
+
+	for _, r := range str {
+		upper = upper || unicode.IsUpper(r)
+		lower = lower || unicode.IsLower(r)
+		digit = digit || unicode.IsDigit(r)
+	}
+
+	return upper && lower && digit
+}
@@ -0,0 +1,73 @@
+// Copyright (C) 2022 CGI France
+//
+// This file is part of the jsonline library.
+//
+// The jsonline library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The jsonline library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with the jsonline library.  If not, see <http://www.gnu.org/licenses/>.
+
+package jsonline_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cgi-fr/jsonline/pkg/jsonline"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInferTemplate(t *testing.T) {
+	input := `{"id":1,"name":"a","created":"2021-09-24T21:21:21Z","price":1,"lines":[{"sku":"A"}]}
+{"id":2,"name":null,"birth":"2021-09-24","created":"2021-09-25T10:00:00+02:00","price":2.5,"key":"aGVsbG8=","tags":["x"]}
+{"id":3,"name":"c","key":"d29ybGQ=","mixed":1,"address":{"city":"Paris"}}
+{"id":4,"name":"d","mixed":"one"}
+{"id":5,"name":"not read"}`
+
+	template, inference, err := jsonline.InferTemplate(jsonline.NewImporter(strings.NewReader(input)), 4)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, inference.Lines)
+
+	names := []string{}
+	for _, column := range inference.Columns {
+		names = append(names, column.Name)
+	}
+
+	assert.Equal(t,
+		[]string{"id", "name", "birth", "created", "price", "key", "mixed", "address", "tags", "lines"}, names)
+
+	columns := map[string]*jsonline.InferredColumn{}
+	for _, column := range inference.Columns {
+		columns[column.Name] = column
+	}
+
+	assert.Equal(t, jsonline.Numeric, columns["id"].Format)
+	assert.Equal(t, int64(0), columns["id"].RawType)
+	assert.Equal(t, float64(0), columns["price"].RawType)
+	assert.Equal(t, jsonline.String, columns["name"].Format)
+	assert.Equal(t, 0.25, columns["name"].NullRatio())
+	assert.Equal(t, jsonline.Date, columns["birth"].Format)
+	assert.Equal(t, jsonline.DateTime, columns["created"].Format)
+	assert.Equal(t, jsonline.Binary, columns["key"].Format)
+	assert.True(t, columns["tags"].Array)
+	assert.Equal(t, jsonline.String, columns["tags"].Format)
+	assert.True(t, columns["lines"].Array)
+	assert.Equal(t, "sku", columns["lines"].Columns[0].Name)
+	assert.Equal(t, "city", columns["address"].Columns[0].Name)
+	assert.True(t, columns["mixed"].Mixed())
+	assert.Equal(t, []string{"number", "string"}, columns["mixed"].Types)
+	assert.Equal(t, jsonline.Auto, columns["mixed"].Format)
+
+	row, err := template.CreateRow([]byte(`{"id":"7","price":"3","created":"2021-09-24T21:21:21Z"}`))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), row.GetOrNil("id"))
+	assert.Equal(t, float64(3), row.GetOrNil("price"))
+}
@@ -0,0 +1,751 @@
+// Copyright (C) 2022 CGI France
+//
+// This file is part of the jsonline library.
+//
+// The jsonline library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The jsonline library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with the jsonline library.  If not, see <http://www.gnu.org/licenses/>.
+//
+// Linking this library statically or dynamically with other modules is
+// making a combined work based on this library.  Thus, the terms and
+// conditions of the GNU General Public License cover the whole
+// combination.
+//
+// As a special exception, the copyright holders of this library give you
+// permission to link this library with independent modules to produce an
+// executable, regardless of the license terms of these independent
+// modules, and to copy and distribute the resulting executable under
+// terms of your choice, provided that you also meet, for each linked
+// independent module, the terms and conditions of the license of that
+// module.  An independent module is a module which is not derived from
+// or based on this library.  If you modify this library, you may extend
+// this exception to your version of the library, but you are not
+// obligated to do so.  If you do not wish to do so, delete this
+// exception statement from your version.
+
+package jsonline
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+
+	"github.com/cgi-fr/jsonline/pkg/cast"
+)
+
+// JSONSchemaDraft is the JSON Schema dialect used by exported schemas.
+const JSONSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// JSONSchema is the subset of JSON Schema (draft 2020-12) that can be converted to and from a template.
+type JSONSchema struct {
+	Schema               string
+	Type                 []string // null is listed if the value is nullable
+	Format               string   // date, date-time, duration, uuid, ipv4, ipv6, uri or email
+	ContentEncoding      string   // base64, base32 or base16
+	ContentMediaType     string   // application/json
+	Properties           []JSONSchemaProperty
+	Required             []string
+	AdditionalProperties *bool
+	Items                *JSONSchema
+	Enum                 []interface{}
+	Pattern              string
+	Minimum              interface{}
+	Maximum              interface{}
+	MinLength            *int
+	MaxLength            *int
+	MinItems             *int
+	MaxItems             *int
+	Default              interface{}
+}
+
+// JSONSchemaProperty is a property of an object schema, properties are kept in order of declaration.
+type JSONSchemaProperty struct {
+	Name   string
+	Schema *JSONSchema
+}
+
+// NewJSONSchema exports the template as a JSON Schema, columns are nullable unless constrained by NotNull, and default
+// values are not exported.
+func NewJSONSchema(t Template) *JSONSchema {
+	s := objectSchema(t.CreateRowEmpty())
+	s.Schema = JSONSchemaDraft
+
+	return s
+}
+
+func objectSchema(r Row) *JSONSchema {
+	s := &JSONSchema{Type: []string{"object"}, Properties: []JSONSchemaProperty{}} //nolint:exhaustivestruct
+
+	var sch *schema
+	if rr, ok := r.(*row); ok && rr.schema != nil {
+		sch = rr.schema
+	} else {
+		sch = &schema{} //nolint:exhaustivestruct
+	}
+
+	iter := r.IterValues()
+	for key, val, ok := iter(); ok; key, val, ok = iter() {
+		if val.GetFormat() == Hidden {
+			continue
+		}
+
+		prop := valueSchema(val)
+		nullable := true
+
+		for _, constraint := range sch.constraints[key] {
+			switch c := constraint.(type) {
+			case required:
+				s.Required = append(s.Required, key)
+			case notNull:
+				nullable = false
+			default:
+				prop.constrain(c)
+			}
+		}
+
+		if nullable && len(prop.Type) > 0 {
+			prop.Type = append(prop.Type, "null")
+		}
+
+		s.Properties = append(s.Properties, JSONSchemaProperty{Name: key, Schema: prop})
+	}
+
+	if sch.extra != ExtraColumnsKeep {
+		additional := false
+		s.AdditionalProperties = &additional
+	}
+
+	return s
+}
+
+//nolint:cyclop
+func valueSchema(val Value) *JSONSchema {
+	switch v := val.(type) {
+	case Row:
+		return objectSchema(v)
+	case *array:
+		return &JSONSchema{Type: []string{"array"}, Items: valueSchema(v.empty())} //nolint:exhaustivestruct
+	}
+
+	s := &JSONSchema{} //nolint:exhaustivestruct
+
+	switch val.GetFormat() {
+	case String:
+		s.Type = []string{"string"}
+	case Numeric:
+		if isInteger(val.GetRawType()) {
+			s.Type = []string{"integer"}
+		} else {
+			s.Type = []string{"number"}
+		}
+	case Decimal:
+		s.Type = []string{"number"}
+	case Duration:
+		durationSchema(s, val)
+	case Boolean:
+		s.Type = []string{"boolean"}
+	case Binary:
+		s.Type = []string{"string"}
+		s.ContentEncoding = contentEncoding(val)
+	case Date:
+		s.Type = []string{"string"}
+		s.Format = standardLayout(val, "date")
+	case DateTime:
+		s.Type = []string{"string"}
+		s.Format = standardLayout(val, "date-time")
+	case Timestamp, TimestampMilli, TimestampMicro, TimestampNano:
+		s.Type = []string{"integer"}
+	case UUID, IPv4, IPv6, CIDR, URL, Email:
+		s.Type = []string{"string"}
+		s.Format = semanticSchemaFormats[val.GetFormat()]
+	case JSON:
+		s.Type = []string{"string"}
+		s.ContentMediaType = "application/json"
+	case Auto, Hidden:
+	}
+
+	return s
+}
+
+// semanticSchemaFormats are the JSON Schema formats of the semantic formats (CIDR has no standard format).
+//
+//nolint:gochecknoglobals
+var semanticSchemaFormats = map[Format]string{
+	UUID:  "uuid",
+	IPv4:  "ipv4",
+	IPv6:  "ipv6",
+	URL:   "uri",
+	Email: "email",
+}
+
+// contentEncoding returns the JSON Schema content encoding of a binary, or an empty string if the encoding has no
+// standard name.
+func contentEncoding(val Value) string {
+	var opts *valueOptions
+	if typed, ok := val.(*value); ok {
+		opts = typed.opts
+	}
+
+	switch opts.binaryEncoding() {
+	case Base64:
+		return "base64"
+	case Base32:
+		return "base32"
+	case Hex:
+		return "base16"
+	case Base64Raw, Base64URL, Base64URLRaw, Base32Raw:
+	}
+
+	return ""
+}
+
+// durationSchema sets the type of the schema from the notation of the duration.
+func durationSchema(s *JSONSchema, val Value) {
+	s.Type = []string{"string"}
+
+	if typed, ok := val.(*value); ok && typed.opts != nil {
+		switch typed.opts.notation {
+		case DurationISO8601:
+			s.Format = "duration"
+		case DurationSeconds, DurationMillis:
+			s.Type = []string{"number"}
+		case DurationGo:
+		}
+	}
+}
+
+// standardLayout returns the JSON Schema format of a date, or an empty string if the value has a custom layout.
+func standardLayout(val Value, format string) string {
+	if typed, ok := val.(*value); ok && typed.opts != nil && len(typed.opts.layouts) > 0 {
+		return ""
+	}
+
+	return format
+}
+
+func isInteger(rawtype RawType) bool {
+	if rawtype == nil {
+		return false
+	}
+
+	switch reflect.TypeOf(rawtype).Kind() { //nolint:exhaustive
+	case reflect.Int, reflect.Int64, reflect.Int32, reflect.Int16, reflect.Int8,
+		reflect.Uint, reflect.Uint64, reflect.Uint32, reflect.Uint16, reflect.Uint8:
+		return true
+	default:
+		return false
+	}
+}
+
+// constrain adds the keyword matching the constraint, constraints without JSON Schema equivalent are ignored.
+func (s *JSONSchema) constrain(constraint Constraint) {
+	isArray := len(s.Type) > 0 && s.Type[0] == "array"
+	isNumber := len(s.Type) > 0 && (s.Type[0] == "integer" || s.Type[0] == "number")
+
+	switch c := constraint.(type) {
+	case enum:
+		s.Enum = c.values
+	case pattern:
+		s.Pattern = c.re.String()
+	case bound:
+		switch {
+		case isNumber && c.min:
+			s.Minimum = c.limit
+		case isNumber:
+			s.Maximum = c.limit
+		}
+	case length:
+		limit := c.limit
+
+		switch {
+		case isArray && c.min:
+			s.MinItems = &limit
+		case isArray:
+			s.MaxItems = &limit
+		case c.min:
+			s.MinLength = &limit
+		default:
+			s.MaxLength = &limit
+		}
+	}
+}
+
+// Nullable returns true if the schema accepts null values.
+func (s *JSONSchema) Nullable() bool {
+	if len(s.Type) == 0 {
+		return true
+	}
+
+	for _, typ := range s.Type {
+		if typ == "null" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// types returns the types of the schema excluding null.
+func (s *JSONSchema) types() []string {
+	result := []string{}
+
+	for _, typ := range s.Type {
+		if typ != "null" {
+			result = append(result, typ)
+		}
+	}
+
+	return result
+}
+
+// IsObject returns true if the schema describes an object with properties.
+func (s *JSONSchema) IsObject() bool {
+	types := s.types()
+
+	return len(types) == 1 && types[0] == "object" && len(s.Properties) > 0
+}
+
+// IsArray returns true if the schema describes an array.
+func (s *JSONSchema) IsArray() bool {
+	types := s.types()
+
+	return len(types) == 1 && types[0] == "array"
+}
+
+// ValueFormat returns the format and rawtype matching the schema of a scalar value (or the elements of an array).
+func (s *JSONSchema) ValueFormat() (Format, RawType) {
+	if s.IsArray() {
+		if s.Items == nil {
+			return Auto, nil
+		}
+
+		return s.Items.ValueFormat()
+	}
+
+	types := s.types()
+	if len(types) != 1 {
+		return Auto, nil
+	}
+
+	switch types[0] {
+	case "string":
+		switch {
+		case s.Format == "date-time":
+			return DateTime, nil
+		case s.Format == "date":
+			return Date, nil
+		case s.Format == "uuid":
+			return UUID, nil
+		case s.Format == "ipv4":
+			return IPv4, nil
+		case s.Format == "ipv6":
+			return IPv6, nil
+		case s.Format == "uri":
+			return URL, nil
+		case s.Format == "email":
+			return Email, nil
+		case s.Format == "duration":
+			return Duration, nil
+		case s.ContentEncoding == "base64", s.ContentEncoding == "base32", s.ContentEncoding == "base16":
+			return Binary, nil
+		case s.ContentMediaType == "application/json":
+			return JSON, nil
+		default:
+			return String, nil
+		}
+	case "integer":
+		return Numeric, int64(0)
+	case "number":
+		return Numeric, nil
+	case "boolean":
+		return Boolean, nil
+	default:
+		return Auto, nil
+	}
+}
+
+// ValueOptions returns the options matching the schema of a scalar value (or the elements of an array), e.g. : the
+// encoding of a binary or the notation of a duration.
+func (s *JSONSchema) ValueOptions() []ValueOption {
+	if s.IsArray() {
+		if s.Items == nil {
+			return nil
+		}
+
+		return s.Items.ValueOptions()
+	}
+
+	switch {
+	case s.Format == "duration":
+		return []ValueOption{Notation(DurationISO8601)}
+	case s.ContentEncoding == "base32":
+		return []ValueOption{Encoding(Base32)}
+	case s.ContentEncoding == "base16":
+		return []ValueOption{Encoding(Hex)}
+	}
+
+	return nil
+}
+
+// Template imports the schema of an object as a template, nested objects and arrays of objects are imported as sub
+// templates.
+func (s *JSONSchema) Template() (Template, error) {
+	if types := s.types(); len(types) > 0 && (len(types) != 1 || types[0] != "object") {
+		return nil, fmt.Errorf("%w: the schema must describe an object, not %v", ErrInvalidTemplate, types)
+	}
+
+	t := NewTemplate()
+
+	if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+		t.WithExtraColumns(ExtraColumnsError)
+	}
+
+	for _, prop := range s.Properties {
+		if err := s.importProperty(t, prop); err != nil {
+			return nil, err
+		}
+	}
+
+	return t, nil
+}
+
+//nolint:cyclop
+func (s *JSONSchema) importProperty(t Template, prop JSONSchemaProperty) error {
+	name, ps := prop.Name, prop.Schema
+
+	switch {
+	case ps.IsObject():
+		sub, err := ps.Template()
+		if err != nil {
+			return err
+		}
+
+		t.WithRow(name, sub)
+	case ps.IsArray() && ps.Items != nil && ps.Items.IsObject():
+		sub, err := ps.Items.Template()
+		if err != nil {
+			return err
+		}
+
+		t.WithArrayOfRows(name, sub)
+	case ps.IsArray():
+		format, rawtype := ps.ValueFormat()
+		t.WithArray(name, format, rawtype, ps.ValueOptions()...)
+	default:
+		format, rawtype := ps.ValueFormat()
+		t.With(name, format, rawtype, ps.ValueOptions()...)
+	}
+
+	constraints, err := ps.constraints()
+	if err != nil {
+		return fmt.Errorf("%w: property %s: %v", ErrInvalidTemplate, name, err)
+	}
+
+	// a missing column is null, only required properties can be constrained to not null
+	for _, req := range s.Required {
+		if req == name && !ps.Nullable() {
+			constraints = append([]Constraint{Required(), NotNull()}, constraints...)
+		} else if req == name {
+			constraints = append([]Constraint{Required()}, constraints...)
+		}
+	}
+
+	if len(constraints) > 0 {
+		t.WithConstraints(name, constraints...)
+	}
+
+	if ps.Default != nil {
+		t.WithDefault(name, Literal(ps.Default))
+	}
+
+	return nil
+}
+
+//nolint:cyclop
+func (s *JSONSchema) constraints() ([]Constraint, error) {
+	constraints := []Constraint{}
+
+	if len(s.Enum) > 0 {
+		constraints = append(constraints, Enum(s.Enum...))
+	}
+
+	if len(s.Pattern) > 0 {
+		re, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("%w", err)
+		}
+
+		constraints = append(constraints, Pattern(re))
+	}
+
+	if s.Minimum != nil {
+		constraints = append(constraints, Min(s.Minimum))
+	}
+
+	if s.Maximum != nil {
+		constraints = append(constraints, Max(s.Maximum))
+	}
+
+	for _, limit := range []*int{s.MinLength, s.MinItems} {
+		if limit != nil {
+			constraints = append(constraints, MinLength(*limit))
+		}
+	}
+
+	for _, limit := range []*int{s.MaxLength, s.MaxItems} {
+		if limit != nil {
+			constraints = append(constraints, MaxLength(*limit))
+		}
+	}
+
+	return constraints, nil
+}
+
+// MarshalJSON writes the schema with properties in order of declaration.
+func (s *JSONSchema) MarshalJSON() ([]byte, error) {
+	return s.row().MarshalJSON()
+}
+
+//nolint:cyclop
+func (s *JSONSchema) row() Row {
+	r := NewRow()
+
+	setIf := func(key string, val interface{}, ok bool) {
+		if ok {
+			r.Set(key, val)
+		}
+	}
+
+	setIf("$schema", s.Schema, len(s.Schema) > 0)
+
+	switch len(s.Type) {
+	case 0:
+	case 1:
+		r.Set("type", s.Type[0])
+	default:
+		r.Set("type", s.Type)
+	}
+
+	setIf("format", s.Format, len(s.Format) > 0)
+	setIf("contentEncoding", s.ContentEncoding, len(s.ContentEncoding) > 0)
+	setIf("contentMediaType", s.ContentMediaType, len(s.ContentMediaType) > 0)
+
+	if s.Properties != nil {
+		properties := NewRow()
+		for _, prop := range s.Properties {
+			properties.Set(prop.Name, prop.Schema.row())
+		}
+
+		r.Set("properties", properties)
+	}
+
+	setIf("required", s.Required, len(s.Required) > 0)
+
+	if s.AdditionalProperties != nil {
+		r.Set("additionalProperties", *s.AdditionalProperties)
+	}
+
+	if s.Items != nil {
+		r.Set("items", s.Items.row())
+	}
+
+	setIf("enum", s.Enum, len(s.Enum) > 0)
+	setIf("pattern", s.Pattern, len(s.Pattern) > 0)
+	setIf("minimum", s.Minimum, s.Minimum != nil)
+	setIf("maximum", s.Maximum, s.Maximum != nil)
+
+	for _, limit := range []struct {
+		key   string
+		value *int
+	}{{"minLength", s.MinLength}, {"maxLength", s.MaxLength}, {"minItems", s.MinItems}, {"maxItems", s.MaxItems}} {
+		if limit.value != nil {
+			r.Set(limit.key, *limit.value)
+		}
+	}
+
+	setIf("default", s.Default, s.Default != nil)
+
+	return r
+}
+
+// UnmarshalJSON reads the schema, unsupported keywords are ignored.
+func (s *JSONSchema) UnmarshalJSON(data []byte) error {
+	r := NewRow()
+	if err := r.UnmarshalJSON(data); err != nil {
+		return err
+	}
+
+	result, err := schemaFromRow(r)
+	if err != nil {
+		return err
+	}
+
+	*s = *result
+
+	return nil
+}
+
+//nolint:cyclop,funlen
+func schemaFromRow(r Row) (*JSONSchema, error) {
+	s := &JSONSchema{} //nolint:exhaustivestruct
+
+	var err error
+
+	iter := r.Iter()
+	for key, val, ok := iter(); ok && err == nil; key, val, ok = iter() {
+		switch key {
+		case "$schema":
+			s.Schema, err = toString(val)
+		case "type":
+			s.Type, err = toStrings(val)
+		case "format":
+			s.Format, err = toString(val)
+		case "contentEncoding":
+			s.ContentEncoding, err = toString(val)
+		case "contentMediaType":
+			s.ContentMediaType, err = toString(val)
+		case "properties":
+			s.Properties, err = propertiesFromRow(val)
+		case "required":
+			s.Required, err = toStrings(val)
+		case "additionalProperties":
+			if b, ok := val.(bool); ok {
+				s.AdditionalProperties = &b
+			}
+		case "items":
+			if items, ok := val.(Row); ok {
+				s.Items, err = schemaFromRow(items)
+			}
+		case "enum":
+			enum, _ := val.([]interface{})
+			s.Enum, _ = nativeNumbers(enum).([]interface{})
+		case "pattern":
+			s.Pattern, err = toString(val)
+		case "minimum":
+			s.Minimum = nativeNumbers(val)
+		case "maximum":
+			s.Maximum = nativeNumbers(val)
+		case "minLength":
+			s.MinLength, err = toIntPointer(val)
+		case "maxLength":
+			s.MaxLength, err = toIntPointer(val)
+		case "minItems":
+			s.MinItems, err = toIntPointer(val)
+		case "maxItems":
+			s.MaxItems, err = toIntPointer(val)
+		case "default":
+			s.Default = nativeNumbers(val)
+		case "$ref", "anyOf", "oneOf", "allOf", "not":
+			return nil, fmt.Errorf("%w: unsupported keyword %s", ErrInvalidTemplate, key)
+		}
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTemplate, err)
+	}
+
+	return s, nil
+}
+
+func propertiesFromRow(val interface{}) ([]JSONSchemaProperty, error) {
+	properties, ok := val.(Row)
+	if !ok {
+		return nil, fmt.Errorf("%w: properties must be an object", ErrInvalidTemplate)
+	}
+
+	result := []JSONSchemaProperty{}
+
+	iter := properties.Iter()
+	for key, val, ok := iter(); ok; key, val, ok = iter() {
+		prop, isRow := val.(Row)
+		if !isRow {
+			return nil, fmt.Errorf("%w: property %s must be an object", ErrInvalidTemplate, key)
+		}
+
+		s, err := schemaFromRow(prop)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, JSONSchemaProperty{Name: key, Schema: s})
+	}
+
+	return result, nil
+}
+
+func toString(val interface{}) (string, error) {
+	str, err := cast.ToString(val)
+	if err != nil {
+		return "", fmt.Errorf("%w", err)
+	}
+
+	return str.(string), nil
+}
+
+func toStrings(val interface{}) ([]string, error) {
+	values, ok := val.([]interface{})
+	if !ok {
+		str, err := toString(val)
+
+		return []string{str}, err
+	}
+
+	result := make([]string, 0, len(values))
+
+	for _, v := range values {
+		str, err := toString(v)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, str)
+	}
+
+	return result, nil
+}
+
+func toIntPointer(val interface{}) (*int, error) {
+	i, err := cast.ToInt(val)
+	if err != nil {
+		return nil, fmt.Errorf("%w", err)
+	}
+
+	result := i.(int)
+
+	return &result, nil
+}
+
+// nativeNumbers converts JSON numbers to int64 or float64.
+func nativeNumbers(v interface{}) interface{} {
+	switch value := v.(type) {
+	case json.Number:
+		if i, err := value.Int64(); err == nil {
+			return i
+		}
+
+		if f, err := value.Float64(); err == nil {
+			return f
+		}
+
+		return value.String()
+	case []interface{}:
+		result := make([]interface{}, 0, len(value))
+		for _, elem := range value {
+			result = append(result, nativeNumbers(elem))
+		}
+
+		return result
+	default:
+		return v
+	}
+}
@@ -0,0 +1,204 @@
+// Copyright (C) 2022 CGI France
+//
+// This file is part of the jsonline library.
+//
+// The jsonline library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The jsonline library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with the jsonline library.  If not, see <http://www.gnu.org/licenses/>.
+
+package jsonline_test
+
+import (
+	"encoding/json"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/cgi-fr/jsonline/pkg/jsonline"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJSONSchemaExport(t *testing.T) {
+	template := jsonline.NewTemplate().
+		WithMappedNumeric("id", int64(0)).
+		WithString("status").
+		WithDateTime("created").
+		WithBinary("key").
+		WithHidden("secret").
+		WithRow("address", jsonline.NewTemplate().WithString("city")).
+		WithArrayOfRows("lines", jsonline.NewTemplate().WithNumeric("qty")).
+		WithConstraints("id", jsonline.Required(), jsonline.NotNull(), jsonline.Min(1)).
+		WithConstraints("status", jsonline.Enum("new", "done"), jsonline.Pattern(regexp.MustCompile("^[a-z]+$"))).
+		WithConstraints("lines", jsonline.MaxLength(10)).
+		WithExtraColumns(jsonline.ExtraColumnsError)
+
+	result, err := json.Marshal(jsonline.NewJSONSchema(template))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"type": "object",
+		"properties": {
+			"id": {"type": "integer", "minimum": 1},
+			"status": {"type": ["string", "null"], "enum": ["new", "done"], "pattern": "^[a-z]+$"},
+			"created": {"type": ["string", "null"], "format": "date-time"},
+			"key": {"type": ["string", "null"], "contentEncoding": "base64"},
+			"address": {"type": ["object", "null"], "properties": {"city": {"type": ["string", "null"]}}},
+			"lines": {
+				"type": ["array", "null"],
+				"items": {"type": "object", "properties": {"qty": {"type": ["number", "null"]}}},
+				"maxItems": 10
+			}
+		},
+		"required": ["id"],
+		"additionalProperties": false
+	}`, string(result))
+	assert.Regexp(t, `^\{"\$schema":.*"id".*"status".*"created".*"key".*"address".*"lines"`, string(result))
+}
+
+func TestJSONSchemaImport(t *testing.T) {
+	schema := &jsonline.JSONSchema{} //nolint:exhaustivestruct
+	err := json.Unmarshal([]byte(`{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"type": "object",
+		"properties": {
+			"status": {"type": "string", "enum": ["new", "done"], "default": "new"},
+			"id": {"type": "integer", "minimum": 1},
+			"created": {"type": ["string", "null"], "format": "date"},
+			"address": {"type": "object", "properties": {"city": {"type": "string"}}, "required": ["city"]},
+			"tags": {"type": "array", "items": {"type": "string"}, "maxItems": 2},
+			"nick": {"type": "string"}
+		},
+		"required": ["id"],
+		"additionalProperties": false
+	}`), schema)
+	assert.NoError(t, err)
+
+	template, err := schema.Template()
+	assert.NoError(t, err)
+
+	row, err := template.CreateRow([]byte(`{"id":"2","created":null,"address":{"city":"Paris"},"tags":["a"]}`))
+	assert.NoError(t, err)
+	assert.Equal(t, `{"status":"new","id":2,"created":null,"address":{"city":"Paris"},"tags":["a"],"nick":null}`,
+		row.String())
+
+	_, err = template.CreateRow([]byte(`{"status":"old","id":0,"address":{"city":null},"tags":["a","b","c"]}`))
+
+	var validationError *jsonline.ValidationError
+
+	assert.ErrorAs(t, err, &validationError)
+
+	paths := []string{}
+	for _, violation := range validationError.Violations {
+		paths = append(paths, violation.Path+":"+violation.Constraint)
+	}
+
+	assert.Equal(t, []string{"status:enum", "id:min", "address.city:nullable", "tags:maxLength"}, paths)
+
+	_, err = template.CreateRow([]byte(`{"id":1,"extra":true}`))
+	assert.ErrorIs(t, err, jsonline.ErrExtraColumn)
+
+	// optional properties can be missing even if their type excludes null
+	_, err = template.CreateRow([]byte(`{"id":1}`))
+	assert.NoError(t, err)
+}
+
+func TestJSONSchemaImportUnsupported(t *testing.T) {
+	for _, keyword := range []string{"$ref", "anyOf", "oneOf", "allOf"} {
+		schema := &jsonline.JSONSchema{} //nolint:exhaustivestruct
+		err := json.Unmarshal([]byte(`{"type":"object","properties":{"id":{"`+keyword+`":[]}}}`), schema)
+		assert.ErrorIs(t, err, jsonline.ErrInvalidTemplate, keyword)
+	}
+
+	schema := &jsonline.JSONSchema{} //nolint:exhaustivestruct
+	assert.NoError(t, json.Unmarshal([]byte(`{"type":"string"}`), schema))
+
+	_, err := schema.Template()
+	assert.ErrorIs(t, err, jsonline.ErrInvalidTemplate)
+}
+
+func TestJSONSchemaSemanticFormats(t *testing.T) {
+	template := jsonline.NewTemplate().
+		With("id", jsonline.UUID, nil).
+		With("network", jsonline.CIDR, nil).
+		With("site", jsonline.URL, nil)
+
+	result, err := json.Marshal(jsonline.NewJSONSchema(template))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"type": "object",
+		"properties": {
+			"id": {"type": ["string", "null"], "format": "uuid"},
+			"network": {"type": ["string", "null"]},
+			"site": {"type": ["string", "null"], "format": "uri"}
+		}
+	}`, string(result))
+
+	schema := &jsonline.JSONSchema{} //nolint:exhaustivestruct
+	assert.NoError(t, json.Unmarshal([]byte(`{"type": "string", "format": "ipv6"}`), schema))
+
+	format, rawtype := schema.ValueFormat()
+	assert.Equal(t, jsonline.IPv6, format)
+	assert.Nil(t, rawtype)
+}
+
+func TestJSONSchemaBinaryEncodings(t *testing.T) {
+	testdatas := []struct {
+		encoding jsonline.BinaryEncoding
+		schema   string
+		expected string
+	}{
+		{jsonline.Base64, "base64", `{"key":"aGVsbG8="}`},
+		{jsonline.Base32, "base32", `{"key":"NBSWY3DP"}`},
+		{jsonline.Hex, "base16", `{"key":"68656c6c6f"}`},
+	}
+
+	for _, td := range testdatas {
+		t.Run(td.schema, func(t *testing.T) {
+			template := jsonline.NewTemplate().With("key", jsonline.Binary, nil, jsonline.Encoding(td.encoding))
+
+			data, err := json.Marshal(jsonline.NewJSONSchema(template))
+			assert.NoError(t, err)
+			assert.Contains(t, string(data), `"contentEncoding":"`+td.schema+`"`)
+
+			schema := &jsonline.JSONSchema{} //nolint:exhaustivestruct
+			assert.NoError(t, json.Unmarshal(data, schema))
+
+			imported, err := schema.Template()
+			assert.NoError(t, err)
+
+			row, err := imported.CreateRow([]byte(td.expected))
+			assert.NoError(t, err)
+			assert.Equal(t, []byte("hello"), row.GetOrNil("key"))
+			assert.Equal(t, td.expected, row.String())
+		})
+	}
+}
+
+func TestJSONSchemaDuration(t *testing.T) {
+	template := jsonline.NewTemplate().With("delay", jsonline.Duration, nil, jsonline.Notation(jsonline.DurationISO8601))
+
+	data, err := json.Marshal(jsonline.NewJSONSchema(template))
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `"format":"duration"`)
+
+	schema := &jsonline.JSONSchema{} //nolint:exhaustivestruct
+	assert.NoError(t, json.Unmarshal(data, schema))
+
+	imported, err := schema.Template()
+	assert.NoError(t, err)
+
+	row, err := imported.CreateRow([]byte(`{"delay":"PT1H30M"}`))
+	assert.NoError(t, err)
+	assert.Equal(t, 90*time.Minute, row.GetOrNil("delay"))
+	assert.Equal(t, `{"delay":"PT1H30M"}`, row.String())
+}
@@ -0,0 +1,670 @@
+// Copyright (C) 2022 CGI France
+//
+// This file is part of the jsonline library.
+//
+// The jsonline library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The jsonline library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with the jsonline library.  If not, see <http://www.gnu.org/licenses/>.
+//
+// Linking this library statically or dynamically with other modules is
+// making a combined work based on this library.  Thus, the terms and
+// conditions of the GNU General Public License cover the whole
+// combination.
+//
+// As a special exception, the copyright holders of this library give you
+// permission to link this library with independent modules to produce an
+// executable, regardless of the license terms of these independent
+// modules, and to copy and distribute the resulting executable under
+// terms of your choice, provided that you also meet, for each linked
+// independent module, the terms and conditions of the license of that
+// module.  An independent module is a module which is not derived from
+// or based on this library.  If you modify this library, you may extend
+// this exception to your version of the library, but you are not
+// obligated to do so.  If you do not wish to do so, delete this
+// exception statement from your version.
+
+package jsonline
+
+import (
+	"database/sql"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"net/mail"
+	"net/netip"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+//nolint:gochecknoglobals
+var (
+	plans sync.Map // mapping plans of struct types, computed once per type
+
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+	uuidType     = reflect.TypeOf([16]byte{})
+	bigRatType   = reflect.TypeOf((*big.Rat)(nil))
+	urlType      = reflect.TypeOf((*url.URL)(nil))
+	mailType     = reflect.TypeOf((*mail.Address)(nil))
+	prefixType   = reflect.TypeOf(netip.Prefix{})
+	ipNetType    = reflect.TypeOf((*net.IPNet)(nil))
+	ipType       = reflect.TypeOf(net.IP{})
+
+	unmarshalerTypes = []reflect.Type{
+		reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem(),
+		reflect.TypeOf((*json.Unmarshaler)(nil)).Elem(),
+		reflect.TypeOf((*sql.Scanner)(nil)).Elem(),
+	}
+
+	basicTypes = []reflect.Type{
+		reflect.TypeOf(""), reflect.TypeOf(false), reflect.TypeOf([]byte{}),
+		reflect.TypeOf(int(0)), reflect.TypeOf(int64(0)), reflect.TypeOf(int32(0)), reflect.TypeOf(int16(0)),
+		reflect.TypeOf(int8(0)), reflect.TypeOf(uint(0)), reflect.TypeOf(uint64(0)), reflect.TypeOf(uint32(0)),
+		reflect.TypeOf(uint16(0)), reflect.TypeOf(uint8(0)), reflect.TypeOf(float64(0)), reflect.TypeOf(float32(0)),
+	}
+)
+
+// field is a field of a struct mapped to a column.
+type field struct {
+	name   string
+	index  []int  // index sequence of the field, fields of embedded structs have several indexes
+	format Format // format given by the tag, Auto otherwise
+	tagged bool
+	opts   []ValueOption
+	shape  *shape // mapping of the type of the field
+}
+
+// kind is how a Go type is mapped to a value.
+type kind int
+
+const (
+	plainKind   kind = iota // scalar value
+	pointerKind             // pointer to a mapped type, nil pointers are null values
+	nestedKind              // struct mapped to a sub row
+	sliceKind               // slice mapped to an array
+)
+
+// shape is the mapping of a Go type, computed once per field by planOf.
+type shape struct {
+	typ     reflect.Type
+	kind    kind
+	plain   reflect.Type // predeclared type of a named basic type, typ otherwise
+	rawtype RawType
+	format  Format // format matching the type, used if the field is not tagged
+	elem    *shape // shape of the pointed type or of the elements of a slice
+}
+
+// MappingError is returned when fields of a struct cannot be mapped, the other fields are mapped anyway.
+type MappingError struct {
+	Errors []error
+}
+
+func (e *MappingError) Error() string {
+	messages := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		messages[i] = err.Error()
+	}
+
+	return strings.Join(messages, ", ")
+}
+
+// Is returns true if one of the errors matches the target.
+func (e *MappingError) Is(target error) bool {
+	for _, err := range e.Errors {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// RowFrom creates a row from a struct, each exported field is a column named by the `jsonline` tag of the field (or
+// the name of the field with a lowercase first letter). Nested structs are sub rows and slices are arrays.
+func RowFrom(v interface{}) (Row, error) {
+	value := reflect.ValueOf(v)
+	for value.Kind() == reflect.Ptr && !value.IsNil() {
+		value = value.Elem()
+	}
+
+	if value.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%w: %T", ErrUnsupportedImportType, v)
+	}
+
+	return rowFrom(value)
+}
+
+// TemplateFrom creates a template from the type of a struct, with the same columns as the rows created by RowFrom.
+func TemplateFrom(v interface{}) (Template, error) {
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%w: %T", ErrInvalidTemplate, v)
+	}
+
+	return templateFrom(t, map[reflect.Type]bool{})
+}
+
+// planOf returns the fields of a struct type mapped to columns.
+func planOf(t reflect.Type) ([]field, error) {
+	if plan, ok := plans.Load(t); ok {
+		fields, _ := plan.([]field)
+
+		return fields, nil
+	}
+
+	fields, err := fieldsOf(t, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	plans.Store(t, fields)
+
+	return fields, nil
+}
+
+// resetPlans forgets the mapping plans, they depend on the registered rawtypes.
+func resetPlans() {
+	plans.Range(func(key, _ interface{}) bool {
+		plans.Delete(key)
+
+		return true
+	})
+}
+
+// fieldsOf lists the fields of a struct type, fields of embedded structs are promoted unless the tag gives a name.
+func fieldsOf(t reflect.Type, index []int) ([]field, error) {
+	result := []field{}
+
+	for i := 0; i < t.NumField(); i++ {
+		structField := t.Field(i)
+
+		tag := structField.Tag.Get("jsonline")
+		if tag == "-" {
+			continue
+		}
+
+		f, err := parseTag(tag)
+		if err != nil {
+			return nil, fmt.Errorf("%w on field %s.%s", err, t.Name(), structField.Name)
+		}
+
+		f.index = append(append([]int{}, index...), i)
+		f.shape = shapeOf(structField.Type)
+
+		if structField.Anonymous && f.name == "" && isNested(structField.Type) {
+			if !structField.IsExported() && structField.Type.Kind() == reflect.Ptr {
+				continue
+			}
+
+			embedded, err := fieldsOf(indirect(structField.Type), f.index)
+			if err != nil {
+				return nil, err
+			}
+
+			result = append(result, embedded...)
+
+			continue
+		}
+
+		if !structField.IsExported() {
+			continue
+		}
+
+		if f.name == "" {
+			f.name = LcFirst(structField.Name)
+		}
+
+		result = append(result, f)
+	}
+
+	return result, nil
+}
+
+// parseTag reads a `jsonline:"name,format=descriptor"` tag, the descriptor follows the syntax of ParseDescriptor
+// (e.g. : "format=datetime[02/01/2006]", "format=decimal(18,2)").
+func parseTag(tag string) (field, error) {
+	result := field{format: Auto} //nolint:exhaustivestruct
+	parts := splitTag(tag)
+	result.name = parts[0]
+
+	for _, part := range parts[1:] {
+		key, val, _ := strings.Cut(part, "=")
+
+		switch key {
+		case "format":
+			format, _, opts, err := ParseDescriptor(val)
+			if err != nil {
+				return result, fmt.Errorf("%w: %v", ErrInvalidTag, err)
+			}
+
+			result.format, result.tagged, result.opts = format, true, opts
+		default:
+			return result, fmt.Errorf("%w: unknown option %q", ErrInvalidTag, part)
+		}
+	}
+
+	return result, nil
+}
+
+// splitTag splits a tag on commas, except commas inside the parenthesis or brackets of a descriptor.
+func splitTag(tag string) []string {
+	parts := []string{}
+	depth, start := 0, 0
+
+	for i, c := range tag {
+		switch c {
+		case '(', '[':
+			depth++
+		case ')', ']':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, tag[start:i])
+				start = i + 1
+			}
+		}
+	}
+
+	return append(parts, tag[start:])
+}
+
+// fieldByIndex returns the field at the index sequence, nil embedded pointers are allocated if alloc is true.
+func fieldByIndex(v reflect.Value, index []int, alloc bool) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				if !alloc {
+					return reflect.Value{}, false
+				}
+
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+
+			v = v.Elem()
+		}
+
+		v = v.Field(x)
+	}
+
+	return v, true
+}
+
+func mapRow(r Row, target reflect.Value) error {
+	fields, err := planOf(target.Type())
+	if err != nil {
+		return err
+	}
+
+	errs := []error{}
+
+	for _, f := range fields {
+		value, ok := r.GetValue(f.name)
+		if !ok {
+			continue
+		}
+
+		dest, _ := fieldByIndex(target, f.index, true)
+
+		err := mapRaw(rawOrRow(value), dest, f, f.shape)
+
+		var nested *MappingError
+
+		switch {
+		case err == nil:
+		case errors.As(err, &nested):
+			for _, nestedErr := range nested.Errors {
+				errs = append(errs, fmt.Errorf("%w at %s", nestedErr, f.name))
+			}
+		default:
+			errs = append(errs, fmt.Errorf("%w at %s", err, f.name))
+		}
+	}
+
+	if len(errs) > 0 {
+		return &MappingError{Errors: errs}
+	}
+
+	return nil
+}
+
+// mapRaw sets dest with the raw content of a value, sub rows are mapped to nested structs and arrays to slices.
+//
+//nolint:cyclop
+func mapRaw(raw interface{}, dest reflect.Value, f field, s *shape) error {
+	if value, ok := raw.(Value); ok {
+		raw = rawOrRow(value)
+	}
+
+	t := dest.Type()
+
+	switch {
+	case raw == nil:
+		dest.Set(reflect.Zero(t))
+	case reflect.TypeOf(raw) == t, !f.tagged && reflect.TypeOf(raw).AssignableTo(t):
+		dest.Set(reflect.ValueOf(raw))
+	case s.kind == pointerKind:
+		elem := reflect.New(t.Elem())
+		if err := mapRaw(raw, elem.Elem(), f, s.elem); err != nil {
+			return err
+		}
+
+		dest.Set(elem)
+	case s.kind == nestedKind:
+		row, ok := raw.(Row)
+		if !ok {
+			return fmt.Errorf("%w: %T", ErrNotARow, raw)
+		}
+
+		return mapRow(row, dest)
+	case s.kind == sliceKind:
+		elems := reflect.ValueOf(raw)
+		if elems.Kind() != reflect.Slice && elems.Kind() != reflect.Array {
+			return fmt.Errorf("%w %T to %s", ErrUnsupportedImportType, raw, t)
+		}
+
+		result := reflect.MakeSlice(t, elems.Len(), elems.Len())
+
+		for i := 0; i < elems.Len(); i++ {
+			if err := mapRaw(elems.Index(i).Interface(), result.Index(i), f, s.elem); err != nil {
+				return err
+			}
+		}
+
+		dest.Set(result)
+	default:
+		value := NewValueNil(f.format, s.rawtype, f.opts...)
+		if err := value.Import(raw); err != nil {
+			return fmt.Errorf("%w", err)
+		}
+
+		result := reflect.ValueOf(value.Raw())
+
+		switch {
+		case !result.IsValid():
+			dest.Set(reflect.Zero(t))
+		case result.Type().AssignableTo(t):
+			dest.Set(result)
+		case result.Type().ConvertibleTo(t):
+			dest.Set(result.Convert(t))
+		default:
+			return fmt.Errorf("%w %T to %s", ErrUnsupportedImportType, raw, t)
+		}
+	}
+
+	return nil
+}
+
+func rowFrom(v reflect.Value) (Row, error) {
+	fields, err := planOf(v.Type())
+	if err != nil {
+		return nil, err
+	}
+
+	result := NewRow()
+
+	for _, f := range fields {
+		field, ok := fieldByIndex(v, f.index, false)
+		if !ok {
+			continue
+		}
+
+		value, err := valueFrom(field, f, f.shape)
+		if err != nil {
+			return nil, fmt.Errorf("%w at %s", err, f.name)
+		}
+
+		result.SetValue(f.name, value)
+	}
+
+	return result, nil
+}
+
+func valueFrom(v reflect.Value, f field, s *shape) (Value, error) {
+	switch s.kind {
+	case pointerKind:
+		if v.IsNil() {
+			return NewValueNil(formatFor(f, s.elem), s.elem.rawtype, f.opts...), nil
+		}
+
+		return valueFrom(v.Elem(), f, s.elem)
+	case nestedKind:
+		return rowFrom(v)
+	case sliceKind:
+		result := newArray(formatFor(f, s.elem), s.elem.rawtype, f.opts...)
+
+		if !v.IsNil() {
+			result.values = make([]Value, v.Len())
+		}
+
+		for i := 0; i < v.Len(); i++ {
+			value, err := valueFrom(v.Index(i), f, s.elem)
+			if err != nil {
+				return nil, err
+			}
+
+			result.values[i] = value
+		}
+
+		return result, nil
+	default:
+		raw := v.Interface()
+		if s.plain != s.typ {
+			raw = v.Convert(s.plain).Interface()
+		}
+
+		return NewValue(raw, formatFor(f, s), s.rawtype, f.opts...), nil
+	}
+}
+
+func templateFrom(t reflect.Type, seen map[reflect.Type]bool) (Template, error) {
+	if seen[t] {
+		return nil, fmt.Errorf("%w: recursive type %s", ErrInvalidTemplate, t)
+	}
+
+	seen[t] = true
+	defer delete(seen, t)
+
+	fields, err := planOf(t)
+	if err != nil {
+		return nil, err
+	}
+
+	result := NewTemplate()
+
+	for _, f := range fields {
+		s, array := indirectShape(f.shape), false
+		if s.kind == sliceKind {
+			s, array = indirectShape(s.elem), true
+		}
+
+		switch {
+		case s.kind == nestedKind:
+			sub, err := templateFrom(s.typ, seen)
+			if err != nil {
+				return nil, err
+			}
+
+			if array {
+				result = result.WithArrayOfRows(f.name, sub)
+			} else {
+				result = result.WithRow(f.name, sub)
+			}
+		case array:
+			result = result.WithArray(f.name, formatFor(f, s), s.rawtype, f.opts...)
+		default:
+			result = result.With(f.name, formatFor(f, s), s.rawtype, f.opts...)
+		}
+	}
+
+	return result, nil
+}
+
+func rawOrRow(v Value) interface{} {
+	if row, ok := v.(Row); ok {
+		return row
+	}
+
+	return v.Raw()
+}
+
+// shapeOf computes the mapping of a type, the shapes of pointed types and elements of slices are computed as well.
+func shapeOf(t reflect.Type) *shape {
+	result := &shape{typ: t, kind: plainKind, plain: plain(t), rawtype: nil, format: formatOf(t), elem: nil}
+	result.rawtype = reflect.Zero(result.plain).Interface()
+
+	switch {
+	case t.Kind() == reflect.Ptr && !registered(t):
+		result.kind, result.elem = pointerKind, shapeOf(t.Elem())
+	case isNested(t):
+		result.kind = nestedKind
+	case isSlice(t):
+		result.kind, result.elem = sliceKind, shapeOf(t.Elem())
+	}
+
+	return result
+}
+
+// indirectShape returns the shape of the type pointed by s, unless s is not a pointer.
+func indirectShape(s *shape) *shape {
+	if s.kind == pointerKind {
+		return s.elem
+	}
+
+	return s
+}
+
+// indirect returns the type pointed by t, unless t is a pointer rawtype (e.g. : *big.Rat).
+func indirect(t reflect.Type) reflect.Type {
+	if t.Kind() == reflect.Ptr && !registered(t) {
+		return t.Elem()
+	}
+
+	return t
+}
+
+// registered returns true if the type is a rawtype of the type registry or a custom rawtype.
+func registered(t reflect.Type) bool {
+	if _, ok := customTypes[t]; ok {
+		return true
+	}
+
+	for _, rawtype := range typeRegistry {
+		if reflect.TypeOf(rawtype) == t {
+			return true
+		}
+	}
+
+	return false
+}
+
+// unmarshaler returns true if the type can decode itself (encoding.TextUnmarshaler, json.Unmarshaler, sql.Scanner).
+func unmarshaler(t reflect.Type) bool {
+	for _, u := range unmarshalerTypes {
+		if t.Implements(u) || reflect.PtrTo(t).Implements(u) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isNested returns true if the type (or the type pointed by it) is a struct mapped to a sub row.
+func isNested(t reflect.Type) bool {
+	t = indirect(t)
+
+	return t.Kind() == reflect.Struct && !registered(t) && !unmarshaler(t)
+}
+
+// isSlice returns true if the type is a slice mapped to an array, byte slices are binaries.
+func isSlice(t reflect.Type) bool {
+	return t.Kind() == reflect.Slice && t.Elem().Kind() != reflect.Uint8 && !registered(t) && !unmarshaler(t)
+}
+
+// plain returns the predeclared type of a named basic type (e.g. : string for `type Status string`), other types are
+// returned as is.
+func plain(t reflect.Type) reflect.Type {
+	if t.PkgPath() == "" || registered(t) || unmarshaler(t) {
+		return t
+	}
+
+	for _, basic := range basicTypes {
+		if basic.Kind() == t.Kind() && (t.Kind() != reflect.Slice || t.Elem().Kind() == reflect.Uint8) {
+			return basic
+		}
+	}
+
+	return t
+}
+
+// formatFor returns the format given by the tag of the field, or the format of the type.
+func formatFor(f field, s *shape) Format {
+	if f.tagged {
+		return f.format
+	}
+
+	return s.format
+}
+
+// formatOf returns the format matching a Go type.
+//
+//nolint:cyclop
+func formatOf(t reflect.Type) Format {
+	switch t {
+	case timeType:
+		return DateTime
+	case durationType:
+		return Duration
+	case bigRatType:
+		return Decimal
+	case uuidType:
+		return UUID
+	case urlType:
+		return URL
+	case mailType:
+		return Email
+	case prefixType, ipNetType:
+		return CIDR
+	case ipType:
+		return String
+	}
+
+	switch t.Kind() { //nolint:exhaustive
+	case reflect.String:
+		return String
+	case reflect.Bool:
+		return Boolean
+	case reflect.Int, reflect.Int64, reflect.Int32, reflect.Int16, reflect.Int8,
+		reflect.Uint, reflect.Uint64, reflect.Uint32, reflect.Uint16, reflect.Uint8,
+		reflect.Float64, reflect.Float32:
+		return Numeric
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return Binary
+		}
+	}
+
+	if registered(t) || unmarshaler(t) {
+		return String
+	}
+
+	return Auto
+}
@@ -0,0 +1,185 @@
+// Copyright (C) 2022 CGI France
+//
+// This file is part of the jsonline library.
+//
+// The jsonline library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The jsonline library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with the jsonline library.  If not, see <http://www.gnu.org/licenses/>.
+
+package jsonline_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cgi-fr/jsonline/pkg/cast"
+	"github.com/cgi-fr/jsonline/pkg/jsonline"
+	"github.com/stretchr/testify/assert"
+)
+
+type Audit struct {
+	CreatedBy string    `jsonline:"created_by"`
+	CreatedAt time.Time `jsonline:"created_at,format=datetime[2006-01-02]"`
+}
+
+type Address struct {
+	City string
+	Zip  int
+}
+
+type Order struct {
+	Sku    string
+	Amount float64 `jsonline:"amount,format=decimal(10,2)"`
+}
+
+type Status string
+
+type Customer struct {
+	Audit
+
+	ID        int64 `jsonline:"id"`
+	Name      string
+	Status    Status
+	Nickname  *string
+	Address   Address
+	Shipping  *Address
+	Orders    []Order
+	Tags      []string
+	Secret    string    `jsonline:"-"`
+	Birthdate time.Time `jsonline:"birthdate,format=date"`
+	internal  int
+}
+
+func TestStructMapping(t *testing.T) {
+	template, err := jsonline.TemplateFrom(Customer{})
+	assert.NoError(t, err)
+
+	row, err := template.CreateRow([]byte(`{"created_by":"admin","created_at":"2021-10-01","id":"7","name":"Alice",` +
+		`"status":"active","nickname":null,"address":{"city":"Nantes","zip":44000},"shipping":{"city":"Paris",` +
+		`"zip":"75001"},"orders":[{"sku":"A","amount":"12.5"},{"sku":"B","amount":3}],"tags":["vip"],` +
+		`"birthdate":"2000-01-02"}`))
+	assert.NoError(t, err)
+
+	var customer Customer
+	assert.NoError(t, row.MapToE(&customer))
+
+	expected := Customer{
+		Audit:     Audit{CreatedBy: "admin", CreatedAt: time.Date(2021, time.October, 1, 0, 0, 0, 0, time.UTC)},
+		ID:        7,
+		Name:      "Alice",
+		Status:    "active",
+		Nickname:  nil,
+		Address:   Address{City: "Nantes", Zip: 44000},
+		Shipping:  &Address{City: "Paris", Zip: 75001},
+		Orders:    []Order{{Sku: "A", Amount: 12.5}, {Sku: "B", Amount: 3}},
+		Tags:      []string{"vip"},
+		Secret:    "",
+		Birthdate: time.Date(2000, time.January, 2, 0, 0, 0, 0, time.UTC),
+		internal:  0,
+	}
+	assert.Equal(t, expected, customer)
+
+	reverse, err := jsonline.RowFrom(&customer)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"created_by":"admin","created_at":"2021-10-01","id":7,"name":"Alice","status":"active",`+
+		`"nickname":null,"address":{"city":"Nantes","zip":44000},"shipping":{"city":"Paris","zip":75001},`+
+		`"orders":[{"sku":"A","amount":12.50},{"sku":"B","amount":3.00}],"tags":["vip"],"birthdate":"2000-01-02"}`,
+		reverse.String())
+}
+
+func TestStructMappingWithoutTemplate(t *testing.T) {
+	row, err := jsonline.NewImporter(strings.NewReader(
+		`{"id":12,"name":"Bob","address":{"city":"Lyon"},"tags":["a","b"],"created_at":"2021-10-01"}`,
+	)).ReadOne()
+	assert.NoError(t, err)
+
+	var customer Customer
+	assert.NoError(t, row.MapToE(&customer))
+	assert.Equal(t, int64(12), customer.ID)
+	assert.Equal(t, "Lyon", customer.Address.City)
+	assert.Equal(t, []string{"a", "b"}, customer.Tags)
+	assert.Equal(t, time.Date(2021, time.October, 1, 0, 0, 0, 0, time.UTC), customer.CreatedAt)
+}
+
+func TestStructMappingErrors(t *testing.T) {
+	row := jsonline.NewRow()
+	row.Set("id", "twelve")
+
+	var customer Customer
+	assert.ErrorIs(t, row.MapToE(&customer), cast.ErrUnableToCast)
+	assert.ErrorIs(t, row.MapToE(customer), jsonline.ErrUnsupportedExportType)
+
+	// fields after a failing one are mapped anyway
+	row.Set("name", "Alice")
+	assert.NoError(t, row.SetAtPath("address.city", "Paris"))
+	assert.NoError(t, row.SetAtPath("address.zip", "none"))
+
+	customer = Customer{}
+	err := row.MapToE(&customer)
+
+	var mappingError *jsonline.MappingError
+
+	assert.ErrorAs(t, err, &mappingError)
+	assert.Len(t, mappingError.Errors, 2)
+	assert.ErrorIs(t, err, cast.ErrUnableToCast)
+	assert.Equal(t, "Alice", customer.Name)
+	assert.Equal(t, "Paris", customer.Address.City)
+
+	customer = Customer{}
+	row.MapTo(&customer)
+	assert.Equal(t, "Alice", customer.Name)
+
+	invalid := struct {
+		Name string `jsonline:"name,format=unknown"`
+	}{}
+
+	_, err = jsonline.RowFrom(invalid)
+	assert.ErrorIs(t, err, jsonline.ErrInvalidTag)
+
+	_, err = jsonline.RowFrom(42)
+	assert.ErrorIs(t, err, jsonline.ErrUnsupportedImportType)
+}
+
+type GeoPoint struct {
+	Lat, Lng float64
+}
+
+type Place struct {
+	Name     string
+	Location GeoPoint
+}
+
+func TestStructMappingRegisteredLater(t *testing.T) {
+	place := Place{Name: "Paris", Location: GeoPoint{Lat: 48.85, Lng: 2.35}}
+
+	row, err := jsonline.RowFrom(place)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"name":"Paris","location":{"lat":48.85,"lng":2.35}}`, row.String())
+
+	cast.Register(GeoPoint{}, func(i interface{}) (interface{}, error) {
+		if point, ok := i.(GeoPoint); ok {
+			return point, nil
+		}
+
+		return nil, cast.ErrUnableToCast
+	})
+	assert.NoError(t, jsonline.RegisterRawType("GeoPoint", GeoPoint{}))
+
+	row, err = jsonline.RowFrom(place)
+	assert.NoError(t, err)
+	assert.Equal(t, place.Location, row.GetOrNil("location"))
+
+	var result Place
+	assert.NoError(t, row.MapToE(&result))
+	assert.Equal(t, place, result)
+}
@@ -0,0 +1,504 @@
+// Copyright (C) 2022 CGI France
+//
+// This file is part of the jsonline library.
+//
+// The jsonline library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The jsonline library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with the jsonline library.  If not, see <http://www.gnu.org/licenses/>.
+//
+// Linking this library statically or dynamically with other modules is
+// making a combined work based on this library.  Thus, the terms and
+// conditions of the GNU General Public License cover the whole
+// combination.
+//
+// As a special exception, the copyright holders of this library give you
+// permission to link this library with independent modules to produce an
+// executable, regardless of the license terms of these independent
+// modules, and to copy and distribute the resulting executable under
+// terms of your choice, provided that you also meet, for each linked
+// independent module, the terms and conditions of the license of that
+// module.  An independent module is a module which is not derived from
+// or based on this library.  If you modify this library, you may extend
+// this exception to your version of the library, but you are not
+// obligated to do so.  If you do not wish to do so, delete this
+// exception statement from your version.
+
+package jsonline
+
+import (
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/cgi-fr/jsonline/pkg/cast"
+)
+
+// ValueOption customizes how a value is imported and exported.
+type ValueOption func(*valueOptions)
+
+type valueOptions struct {
+	layouts  []string         // date and datetime layouts, tried in order on import, the first one is used on export
+	location *time.Location   // timezone of imported and exported times
+	assume   *time.Location   // timezone of inputs without zone information
+	digits   *digits          // precision and scale of decimals, nil means exact
+	rounding big.RoundingMode // rounding mode of decimals
+	notation DurationNotation // representation of durations
+	encoding BinaryEncoding   // encoding of binaries
+	bytes    cast.ByteLayout  // layout of numbers held by binaries
+	embedded Template         // template of the objects embedded in JSON strings, nil means any document
+	nulls    []interface{}    // sentinel values imported as null
+	empty    bool             // empty strings are imported as null
+	omitNull bool             // null values are omitted from rows on export
+	nullAs   interface{}      // value exported instead of null, nil means null
+}
+
+// BinaryEncoding is the text encoding of a Binary value.
+type BinaryEncoding int8
+
+const (
+	Base64       BinaryEncoding = iota // Standard base64 encoding with padding (RFC 4648).
+	Base64Raw                          // Standard base64 encoding without padding.
+	Base64URL                          // URL and filename safe base64 encoding with padding.
+	Base64URLRaw                       // URL and filename safe base64 encoding without padding.
+	Base32                             // Standard base32 encoding with padding.
+	Base32Raw                          // Standard base32 encoding without padding.
+	Hex                                // Hexadecimal encoding (lower case on export, any case on import).
+)
+
+// binaryCodec encodes and decodes binaries to and from text.
+type binaryCodec interface {
+	EncodeToString(src []byte) string
+	DecodeString(s string) ([]byte, error)
+}
+
+type hexCodec struct{}
+
+func (hexCodec) EncodeToString(src []byte) string {
+	return hex.EncodeToString(src)
+}
+
+func (hexCodec) DecodeString(s string) ([]byte, error) {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("%w", err)
+	}
+
+	return b, nil
+}
+
+// codec returns the codec of the encoding.
+func (e BinaryEncoding) codec() binaryCodec {
+	switch e {
+	case Base64Raw:
+		return base64.RawStdEncoding
+	case Base64URL:
+		return base64.URLEncoding
+	case Base64URLRaw:
+		return base64.RawURLEncoding
+	case Base32:
+		return base32.StdEncoding
+	case Base32Raw:
+		return base32.StdEncoding.WithPadding(base32.NoPadding)
+	case Hex:
+		return hexCodec{}
+	case Base64:
+	}
+
+	return base64.StdEncoding
+}
+
+// DurationNotation is the representation of a Duration value.
+type DurationNotation int8
+
+const (
+	DurationGo      DurationNotation = iota // Go duration, e.g. : "1h30m0s".
+	DurationISO8601                         // ISO 8601 duration, e.g. : "PT1H30M".
+	DurationSeconds                         // Number of seconds, e.g. : 5400.
+	DurationMillis                          // Number of milliseconds, e.g. : 5400000.
+)
+
+type digits struct {
+	precision int // maximum number of digits, 0 means unlimited
+	scale     int // number of decimals
+}
+
+// Layouts sets the layouts of a Date, DateTime or Timestamp value : Go layouts (e.g. : "02/01/2006 15:04") or
+// strftime layouts (e.g. : "%d/%m/%Y %H:%M"). Layouts are tried in order on import, the first one is used on export.
+func Layouts(layouts ...string) ValueOption {
+	return func(o *valueOptions) {
+		o.layouts = make([]string, 0, len(layouts))
+
+		for _, layout := range layouts {
+			o.layouts = append(o.layouts, goLayout(layout))
+		}
+	}
+}
+
+// Timezone converts the times of a Date or DateTime value to the timezone on import and on export, inputs without zone
+// information are assumed to be in this timezone (unless AssumeTimezone is used).
+func Timezone(loc *time.Location) ValueOption {
+	return func(o *valueOptions) {
+		o.location = loc
+	}
+}
+
+// AssumeTimezone sets the timezone of Date or DateTime inputs without zone information (UTC by default).
+func AssumeTimezone(loc *time.Location) ValueOption {
+	return func(o *valueOptions) {
+		o.assume = loc
+	}
+}
+
+// Precision sets the maximum number of digits (0 means unlimited) and the number of decimals of a Decimal value, as
+// the DECIMAL(precision, scale) SQL type.
+func Precision(precision int, scale int) ValueOption {
+	return func(o *valueOptions) {
+		o.digits = &digits{precision: precision, scale: scale}
+	}
+}
+
+// Rounding sets the rounding mode of a Decimal value (big.ToNearestEven by default).
+func Rounding(mode big.RoundingMode) ValueOption {
+	return func(o *valueOptions) {
+		o.rounding = mode
+	}
+}
+
+// Notation sets the representation of a Duration value on export (DurationGo by default), numbers are read as seconds
+// on import unless the notation is DurationMillis. All notations are accepted on import.
+func Notation(notation DurationNotation) ValueOption {
+	return func(o *valueOptions) {
+		o.notation = notation
+	}
+}
+
+// Encoding sets the text encoding of a Binary value on import and on export (Base64 by default).
+func Encoding(encoding BinaryEncoding) ValueOption {
+	return func(o *valueOptions) {
+		o.encoding = encoding
+	}
+}
+
+// ByteLayout sets the layout of the numbers held by a Binary value with a numeric rawtype (cast.LittleEndian by
+// default).
+func ByteLayout(layout cast.ByteLayout) ValueOption {
+	return func(o *valueOptions) {
+		o.bytes = layout
+	}
+}
+
+// Embedded sets the template of the object embedded in a JSON value, the document must then be an object.
+func Embedded(t Template) ValueOption {
+	return func(o *valueOptions) {
+		o.embedded = t
+	}
+}
+
+// NullIf sets sentinel values imported as null (e.g. : "N/A", -1), values are compared by their string representation.
+func NullIf(values ...interface{}) ValueOption {
+	return func(o *valueOptions) {
+		o.nulls = values
+	}
+}
+
+// EmptyAsNull imports empty strings as null.
+func EmptyAsNull() ValueOption {
+	return func(o *valueOptions) {
+		o.empty = true
+	}
+}
+
+// OmitNull removes the column from the exported row when the value is null.
+func OmitNull() ValueOption {
+	return func(o *valueOptions) {
+		o.omitNull = true
+	}
+}
+
+// ReplaceNull sets the value exported instead of null (e.g. : "N/A").
+func ReplaceNull(replacement interface{}) ValueOption {
+	return func(o *valueOptions) {
+		o.nullAs = replacement
+	}
+}
+
+// isNull returns true if the value must be imported as null.
+func (o *valueOptions) isNull(val interface{}) bool {
+	if val == nil {
+		return true
+	}
+
+	if o == nil {
+		return false
+	}
+
+	str, err := cast.ToString(val)
+	if err != nil {
+		return false
+	}
+
+	if o.empty && str == "" {
+		return true
+	}
+
+	for _, sentinel := range o.nulls {
+		if s, err := cast.ToString(sentinel); err == nil && s == str {
+			return true
+		}
+	}
+
+	return false
+}
+
+// nullValue returns the value exported instead of null.
+func (o *valueOptions) nullValue() interface{} {
+	if o == nil {
+		return nil
+	}
+
+	return o.nullAs
+}
+
+// byteLayout returns the byte layout of the options.
+func (o *valueOptions) byteLayout() cast.ByteLayout {
+	if o == nil {
+		return cast.LittleEndian
+	}
+
+	return o.bytes
+}
+
+// binaryEncoding returns the encoding of the options.
+func (o *valueOptions) binaryEncoding() BinaryEncoding {
+	if o == nil {
+		return Base64
+	}
+
+	return o.encoding
+}
+
+// newOptions returns the options built from the given value options, or nil if there is none.
+func newOptions(opts []ValueOption) *valueOptions {
+	if len(opts) == 0 {
+		return nil
+	}
+
+	result := &valueOptions{} //nolint:exhaustivestruct
+	for _, opt := range opts {
+		opt(result)
+	}
+
+	return result
+}
+
+// valueOptionsOf returns the options of the value as value options.
+func valueOptionsOf(v Value) []ValueOption {
+	var o *valueOptions
+
+	switch typed := v.(type) {
+	case *value:
+		o = typed.opts
+	case *array:
+		o = typed.opts
+	}
+
+	if o == nil {
+		return nil
+	}
+
+	result := []ValueOption{}
+
+	if len(o.layouts) > 0 {
+		result = append(result, Layouts(o.layouts...))
+	}
+
+	if o.location != nil {
+		result = append(result, Timezone(o.location))
+	}
+
+	if o.assume != nil {
+		result = append(result, AssumeTimezone(o.assume))
+	}
+
+	if o.digits != nil {
+		result = append(result, Precision(o.digits.precision, o.digits.scale))
+	}
+
+	if o.rounding != big.ToNearestEven {
+		result = append(result, Rounding(o.rounding))
+	}
+
+	if o.notation != DurationGo {
+		result = append(result, Notation(o.notation))
+	}
+
+	if o.encoding != Base64 {
+		result = append(result, Encoding(o.encoding))
+	}
+
+	if o.bytes != cast.LittleEndian {
+		result = append(result, ByteLayout(o.bytes))
+	}
+
+	if o.embedded != nil {
+		result = append(result, Embedded(o.embedded))
+	}
+
+	if len(o.nulls) > 0 {
+		result = append(result, NullIf(o.nulls...))
+	}
+
+	if o.empty {
+		result = append(result, EmptyAsNull())
+	}
+
+	if o.omitNull {
+		result = append(result, OmitNull())
+	}
+
+	if o.nullAs != nil {
+		result = append(result, ReplaceNull(o.nullAs))
+	}
+
+	return result
+}
+
+//nolint:gochecknoglobals
+var strftimeDirectives = map[byte]string{
+	'Y': "2006",
+	'y': "06",
+	'm': "01",
+	'd': "02",
+	'e': "_2",
+	'j': "002",
+	'H': "15",
+	'I': "03",
+	'M': "04",
+	'S': "05",
+	'f': "000000",
+	'p': "PM",
+	'b': "Jan",
+	'h': "Jan",
+	'B': "January",
+	'a': "Mon",
+	'A': "Monday",
+	'z': "-0700",
+	'Z': "MST",
+	'F': "2006-01-02",
+	'T': "15:04:05",
+	'D': "01/02/06",
+	'%': "%",
+}
+
+// goLayout converts a strftime layout to a Go layout, Go layouts are returned as is.
+func goLayout(layout string) string {
+	if !strings.Contains(layout, "%") {
+		return layout
+	}
+
+	var sb strings.Builder
+
+	for i := 0; i < len(layout); i++ {
+		if layout[i] == '%' && i+1 < len(layout) {
+			if directive, ok := strftimeDirectives[layout[i+1]]; ok {
+				sb.WriteString(directive)
+
+				i++
+
+				continue
+			}
+		}
+
+		sb.WriteByte(layout[i])
+	}
+
+	return sb.String()
+}
+
+// naiveLayout is the layout of ISO 8601 datetimes without zone information.
+const naiveLayout = "2006-01-02T15:04:05.999999999"
+
+// assumed returns the timezone of inputs without zone information.
+func (o *valueOptions) assumed() *time.Location {
+	switch {
+	case o.assume != nil:
+		return o.assume
+	case o.location != nil:
+		return o.location
+	default:
+		return time.UTC
+	}
+}
+
+// parseTime parses the string with the first matching layout, in the given timezone if the layout has no zone
+// information.
+func parseTime(str string, layouts []string, loc *time.Location) (time.Time, error) {
+	var err error
+
+	for _, layout := range layouts {
+		var t time.Time
+		if t, err = time.ParseInLocation(layout, str, loc); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("%w: %q doesn't match layouts %q", ErrUnsupportedImportType, str, layouts)
+}
+
+// parseTimeWithOptions converts the value to a time when the options require it : strings are parsed with the layouts
+// of the options (or as ISO 8601 datetimes with or without zone information), and times are converted to the timezone
+// of the options. Other values are returned as is.
+//
+//nolint:cyclop
+func parseTimeWithOptions(val interface{}, opts *valueOptions) (interface{}, error) {
+	if opts == nil {
+		return val, nil
+	}
+
+	if b, ok := val.([]byte); ok {
+		val = string(b)
+	}
+
+	switch str := val.(type) {
+	case string:
+		switch {
+		case len(opts.layouts) > 0:
+			t, err := parseTime(str, opts.layouts, opts.assumed())
+			if err != nil {
+				return nil, err
+			}
+
+			val = t
+		case opts.location != nil || opts.assume != nil:
+			if t, err := parseTime(str, []string{time.RFC3339Nano, naiveLayout}, opts.assumed()); err == nil {
+				val = t
+			}
+		}
+	case nil:
+		return nil, nil
+	}
+
+	if opts.location == nil {
+		return val, nil
+	}
+
+	// timestamps are converted too, time.Unix would otherwise use the local timezone
+	if t, err := cast.ToTime(val); err == nil {
+		val = t.(time.Time).In(opts.location)
+	}
+
+	return val, nil
+}
@@ -0,0 +1,491 @@
+// Copyright (C) 2022 CGI France
+//
+// This file is part of the jsonline library.
+//
+// The jsonline library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The jsonline library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with the jsonline library.  If not, see <http://www.gnu.org/licenses/>.
+//
+// Linking this library statically or dynamically with other modules is
+// making a combined work based on this library.  Thus, the terms and
+// conditions of the GNU General Public License cover the whole
+// combination.
+//
+// As a special exception, the copyright holders of this library give you
+// permission to link this library with independent modules to produce an
+// executable, regardless of the license terms of these independent
+// modules, and to copy and distribute the resulting executable under
+// terms of your choice, provided that you also meet, for each linked
+// independent module, the terms and conditions of the license of that
+// module.  An independent module is a module which is not derived from
+// or based on this library.  If you modify this library, you may extend
+// this exception to your version of the library, but you are not
+// obligated to do so.  If you do not wish to do so, delete this
+// exception statement from your version.
+
+package jsonline
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/cgi-fr/jsonline/pkg/cast"
+)
+
+// pathSegment is one step of a path expression, it addresses either a key of a row, an index of an array, or every
+// element of a row or an array (wildcard).
+type pathSegment struct {
+	key      string
+	index    int
+	isIndex  bool
+	wildcard bool
+}
+
+// parsePath splits a path expression into segments.
+//
+// Grammar :
+//   - keys are separated by dots : customer.address.zip
+//   - keys containing special characters are quoted : customer."e-mail.pro" or customer["e-mail.pro"]
+//   - array elements are addressed by index, negative indices start from the end : orders[2], orders[-1]
+//   - wildcards address every element of an array or a row : orders[*].sku, customer.*
+//
+//nolint:cyclop,funlen
+func parsePath(path string) ([]pathSegment, error) {
+	segments := []pathSegment{}
+
+	for pos := 0; ; {
+		var (
+			segment pathSegment
+			err     error
+		)
+
+		switch {
+		case pos < len(path) && (path[pos] == '"' || path[pos] == '\''):
+			segment.key, pos, err = readQuoted(path, pos)
+		case pos < len(path) && path[pos] == '[':
+			segment, pos, err = readBracket(path, pos)
+		case pos < len(path) && path[pos] == '*' && (pos+1 == len(path) || path[pos+1] == '.' || path[pos+1] == '['):
+			segment.wildcard = true
+			pos++
+		default:
+			end := strings.IndexAny(path[pos:], ".[")
+			if end < 0 {
+				end = len(path) - pos
+			}
+
+			segment.key = path[pos : pos+end]
+			pos += end
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		segments = append(segments, segment)
+
+		// brackets can follow any segment directly
+		for pos < len(path) && path[pos] == '[' {
+			segment, pos, err = readBracket(path, pos)
+			if err != nil {
+				return nil, err
+			}
+
+			segments = append(segments, segment)
+		}
+
+		switch {
+		case pos == len(path):
+			return segments, nil
+		case path[pos] == '.':
+			pos++
+		default:
+			return nil, fmt.Errorf("%w: unexpected character %q at position %d in %q", ErrInvalidPath, path[pos], pos, path)
+		}
+	}
+}
+
+func readQuoted(path string, pos int) (string, int, error) {
+	quote := path[pos]
+	sb := strings.Builder{}
+
+	for i := pos + 1; i < len(path); i++ {
+		switch path[i] {
+		case '\\':
+			if i+1 < len(path) {
+				i++
+				sb.WriteByte(path[i])
+			}
+		case quote:
+			return sb.String(), i + 1, nil
+		default:
+			sb.WriteByte(path[i])
+		}
+	}
+
+	return "", pos, fmt.Errorf("%w: unterminated quoted key at position %d in %q", ErrInvalidPath, pos, path)
+}
+
+func readBracket(path string, pos int) (pathSegment, int, error) {
+	if pos+1 < len(path) && (path[pos+1] == '"' || path[pos+1] == '\'') {
+		key, end, err := readQuoted(path, pos+1)
+		if err != nil {
+			return pathSegment{}, pos, err
+		}
+
+		if end >= len(path) || path[end] != ']' {
+			return pathSegment{}, pos, fmt.Errorf("%w: missing ']' at position %d in %q", ErrInvalidPath, end, path)
+		}
+
+		return pathSegment{key: key}, end + 1, nil
+	}
+
+	end := strings.IndexByte(path[pos:], ']')
+	if end < 0 {
+		return pathSegment{}, pos, fmt.Errorf("%w: missing ']' after position %d in %q", ErrInvalidPath, pos, path)
+	}
+
+	content := path[pos+1 : pos+end]
+	if content == "*" {
+		return pathSegment{wildcard: true}, pos + end + 1, nil
+	}
+
+	index, err := strconv.Atoi(content)
+	if err != nil {
+		return pathSegment{}, pos, fmt.Errorf("%w: invalid index %q in %q", ErrInvalidPath, content, path)
+	}
+
+	return pathSegment{index: index, isIndex: true}, pos + end + 1, nil
+}
+
+// pathArray gives access to the elements of an array stored inside a row.
+type pathArray interface {
+	length() int
+	element(index int) interface{}
+	set(index int, val interface{}) error
+	setValue(index int, val Value)
+	importValue(index int, val interface{}) error
+	remove(index int)
+}
+
+// rawArray is an untyped JSON array, the slice is always reloaded from its holder so that successive modifications are
+// not lost.
+type rawArray struct {
+	load  func() []interface{}
+	store func([]interface{})
+}
+
+func (a *rawArray) length() int {
+	return len(a.load())
+}
+
+func (a *rawArray) element(index int) interface{} {
+	return a.load()[index]
+}
+
+func (a *rawArray) set(index int, val interface{}) error {
+	a.load()[index] = val
+
+	return nil
+}
+
+func (a *rawArray) setValue(index int, val Value) {
+	a.load()[index] = val
+}
+
+func (a *rawArray) importValue(index int, val interface{}) error {
+	elems := a.load()
+	if existing, ok := elems[index].(Value); ok {
+		return existing.Import(val)
+	}
+
+	elems[index] = val
+
+	return nil
+}
+
+func (a *rawArray) remove(index int) {
+	elems := a.load()
+	a.store(append(elems[:index:index], elems[index+1:]...))
+}
+
+// pathTarget is the location of a value addressed by a path : either a key of a row or an index of an array.
+type pathTarget struct {
+	row   Row
+	key   string
+	array pathArray
+	index int
+}
+
+func (t pathTarget) value() (Value, bool) {
+	elem, exist := t.element()
+	if !exist {
+		return nil, false
+	}
+
+	if value, ok := elem.(Value); ok {
+		return value, true
+	}
+
+	return NewValueAuto(elem), true
+}
+
+// element returns the value stored at the target location, untyped array elements are returned as is (without being
+// wrapped in a Value).
+func (t pathTarget) element() (interface{}, bool) {
+	if t.row != nil {
+		return t.row.GetValue(t.key)
+	}
+
+	return t.array.element(t.index), true
+}
+
+func (t pathTarget) set(val interface{}) error {
+	if current, exist := t.element(); exist {
+		if value, ok := current.(Value); ok {
+			if err := checkRawType(value, val); err != nil {
+				return err
+			}
+		}
+	}
+
+	if t.row != nil {
+		t.row.Set(t.key, val)
+
+		return nil
+	}
+
+	return t.array.set(t.index, val)
+}
+
+// checkRawType returns an error if val can't be cast to the rawtype of the scalar value it replaces.
+func checkRawType(target Value, val interface{}) error {
+	switch target.(type) {
+	case Row, *array:
+		return nil
+	}
+
+	if _, ok := val.(Value); ok || target.GetRawType() == nil || newOptions(valueOptionsOf(target)).isNull(val) {
+		return nil
+	}
+
+	if _, err := cast.To(target.GetRawType(), val); err != nil {
+		return fmt.Errorf("%w %T to %T: %v", ErrUnsupportedImportType, val, target.GetRawType(), err)
+	}
+
+	return nil
+}
+
+func (t pathTarget) setValue(val Value) {
+	if t.row != nil {
+		t.row.SetValue(t.key, val)
+	} else {
+		t.array.setValue(t.index, val)
+	}
+}
+
+func (t pathTarget) importValue(val interface{}) error {
+	if t.row != nil {
+		return t.row.ImportAtKey(t.key, val)
+	}
+
+	return t.array.importValue(t.index, val)
+}
+
+func (t pathTarget) delete() bool {
+	if t.row != nil {
+		return t.row.Delete(t.key)
+	}
+
+	t.array.remove(t.index)
+
+	return true
+}
+
+// container returns the row or the array held by v, or nil if v is a scalar.
+func container(v interface{}, store func([]interface{})) interface{} {
+	switch typed := v.(type) {
+	case Row:
+		return typed
+	case *array:
+		return typed
+	case []interface{}:
+		return &rawArray{
+			load:  func() []interface{} { return typed },
+			store: func(elems []interface{}) { typed = elems; store(elems) },
+		}
+	case Value:
+		if row, ok := typed.Raw().(Row); ok {
+			return row
+		}
+
+		if elems, ok := typed.Raw().([]interface{}); ok {
+			return &rawArray{
+				load:  func() []interface{} { return elems },
+				store: func(newElems []interface{}) { elems = newElems; setRaw(typed, newElems) },
+			}
+		}
+	}
+
+	return nil
+}
+
+// targets returns the locations addressed by the last segment in the given container. A key segment applied to an array
+// is applied to every row of the array.
+func targets(c interface{}, segment pathSegment) []pathTarget {
+	result := []pathTarget{}
+
+	switch typed := c.(type) {
+	case Row:
+		switch {
+		case segment.wildcard:
+			iter := typed.IterValues()
+			for key, _, ok := iter(); ok; key, _, ok = iter() {
+				result = append(result, pathTarget{row: typed, key: key})
+			}
+		case !segment.isIndex:
+			result = append(result, pathTarget{row: typed, key: segment.key})
+		}
+	case pathArray:
+		length := typed.length()
+
+		switch {
+		case segment.wildcard:
+			for i := 0; i < length; i++ {
+				result = append(result, pathTarget{array: typed, index: i})
+			}
+		case segment.isIndex:
+			if index, ok := normalizeIndex(segment.index, length); ok {
+				result = append(result, pathTarget{array: typed, index: index})
+			}
+		default:
+			for i := 0; i < length; i++ {
+				if row, ok := typed.element(i).(Row); ok {
+					result = append(result, pathTarget{row: row, key: segment.key})
+				}
+			}
+		}
+	}
+
+	return result
+}
+
+func isNull(elem interface{}) bool {
+	if value, ok := elem.(Value); ok {
+		return value == nil || value.Raw() == nil
+	}
+
+	return elem == nil
+}
+
+func normalizeIndex(index int, length int) (int, bool) {
+	if index < 0 {
+		index += length
+	}
+
+	return index, index >= 0 && index < length
+}
+
+// resolveParents walks every segment but the last one and returns the containers holding the addressed values. Missing
+// (or null) rows addressed by a key are created when create is true, they are only written in their parent if attach
+// is true.
+func resolveParents(r Row, segments []pathSegment, create bool, attach bool) ([]interface{}, error) {
+	parents := []interface{}{r}
+
+	for _, segment := range segments[:len(segments)-1] {
+		next := []interface{}{}
+
+		for _, parent := range parents {
+			for _, target := range targets(parent, segment) {
+				elem, exist := target.element()
+
+				if create && target.row != nil && (!exist || isNull(elem)) {
+					sub := NewRow()
+					if attach {
+						target.setValue(sub)
+					}
+
+					next = append(next, sub)
+
+					continue
+				}
+
+				if !exist {
+					continue
+				}
+
+				target := target
+
+				c := container(elem, func(elems []interface{}) { _ = target.set(elems) })
+				if c == nil && create {
+					return nil, fmt.Errorf("%w: %s", ErrNotARow, segment.String())
+				}
+
+				if c != nil {
+					next = append(next, c)
+				}
+			}
+		}
+
+		parents = next
+	}
+
+	return parents, nil
+}
+
+// resolve returns the locations addressed by the path.
+func resolve(r Row, path string, create bool) ([]pathTarget, error) {
+	segments, err := parsePath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if create {
+		// the whole path is resolved before creating missing rows, so that the row is unchanged if it cannot be reached
+		parents, err := resolveParents(r, segments, create, false)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(targetsOf(parents, segments[len(segments)-1])) == 0 {
+			return []pathTarget{}, nil
+		}
+	}
+
+	parents, err := resolveParents(r, segments, create, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return targetsOf(parents, segments[len(segments)-1]), nil
+}
+
+// targetsOf returns the locations addressed by the last segment in every container.
+func targetsOf(parents []interface{}, segment pathSegment) []pathTarget {
+	result := []pathTarget{}
+	for _, parent := range parents {
+		result = append(result, targets(parent, segment)...)
+	}
+
+	return result
+}
+
+func (s pathSegment) String() string {
+	switch {
+	case s.wildcard:
+		return "[*]"
+	case s.isIndex:
+		return fmt.Sprintf("[%d]", s.index)
+	default:
+		return s.key
+	}
+}
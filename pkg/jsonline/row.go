@@ -60,6 +60,9 @@ type Row interface {
 	GetAtPathOrNil(path string) interface{}
 	Set(key string, val interface{})
 	SetAtIndex(index int, val interface{})
+	SetAtPath(path string, val interface{}) error
+	Delete(key string) bool
+	DeleteAtPath(path string) bool
 	Len() int
 	Iter() func() (string, interface{}, bool)
 
@@ -90,17 +93,32 @@ type Row interface {
 	FindValuesAtPath(path string) ([]Value, bool)
 	SetValue(key string, val Value) Row
 	SetValueAtIndex(index int, val Value) Row
+	SetValueAtPath(path string, val Value) error
 	IterValues() func() (string, Value, bool)
 
+	Validate() error
+
 	MapTo(interface{})
+	MapToE(interface{}) error
 }
 
 type m map[string]Value
 
 type row struct {
 	m
-	l    *list.List
-	keys map[string]*list.Element
+	l       *list.List
+	keys    map[string]*list.Element
+	schema  *schema
+	present map[string]struct{} // columns set since the row was created, only tracked if the row has a schema
+}
+
+// schema holds the metadata declared by a template, it is shared by every row created from the template.
+type schema struct {
+	extra       ExtraColumnsPolicy
+	constraints map[string][]Constraint
+	constrained []string // constrained columns in order of declaration
+	defaults    map[string]DefaultValue
+	defaulted   []string // columns with a default value in order of declaration
 }
 
 // NewRow create a new Row.
@@ -121,6 +139,11 @@ func CloneRow(r Row) Row {
 		result.SetValue(k, CloneValue(v))
 	}
 
+	// the schema is set last, cloned columns are not considered present
+	if typed, ok := r.(*row); ok {
+		result.(*row).schema = typed.schema
+	}
+
 	return result
 }
 
@@ -175,6 +198,19 @@ func (r *row) Import(v interface{}) error {
 				return err
 			}
 		}
+	case Row:
+		iter := values.IterValues()
+
+		for key, val, ok := iter(); ok; key, val, ok = iter() {
+			var raw interface{} = val
+			if _, isRow := val.(Row); !isRow {
+				raw = val.Raw()
+			}
+
+			if err := r.ImportAtKey(key, raw); err != nil {
+				return err
+			}
+		}
 	default:
 		return fmt.Errorf("%w", ErrUnsupportedImportType)
 	}
@@ -184,9 +220,15 @@ func (r *row) Import(v interface{}) error {
 
 func (r *row) ImportAtKey(key string, val interface{}) error {
 	if _, ok := r.m[key]; !ok {
+		if accept, err := acceptExtra(r, key); !accept {
+			return err
+		}
+
 		r.keys[key] = r.l.PushBack(key)
 	}
 
+	r.mark(key)
+
 	if value, exist := r.m[key]; exist {
 		if err := value.Import(val); err != nil {
 			return fmt.Errorf("%w", err)
@@ -218,12 +260,19 @@ func (r *row) ImportAtIndex(index int, val interface{}) error {
 }
 
 func (r *row) ImportAtPath(path string, val interface{}) error {
-	if value, exist := r.GetValueAtPath(path); exist {
-		if err := value.Import(val); err != nil {
+	targets, err := resolve(r, path, true)
+	if err != nil {
+		return err
+	}
+
+	if len(targets) == 0 {
+		return fmt.Errorf("%w: %s", ErrPathNotFound, path)
+	}
+
+	for _, target := range targets {
+		if err := target.importValue(val); err != nil {
 			return fmt.Errorf("%w", err)
 		}
-	} else {
-		return fmt.Errorf("%w", ErrPathNotFound)
 	}
 
 	return nil
@@ -304,11 +353,13 @@ func (r *row) Set(key string, val interface{}) {
 		r.keys[key] = r.l.PushBack(key)
 	}
 
+	r.mark(key)
+
 	if value, exist := r.m[key]; exist {
-		if raw, err := cast.To(value.GetRawType(), val); err != nil {
-			r.m[key] = NewValue(raw, value.GetFormat(), value.GetRawType())
+		if assigned, err := assign(value, val); err == nil {
+			r.m[key] = assigned
 		} else {
-			r.m[key] = NewValue(val, value.GetFormat(), value.GetRawType())
+			r.m[key] = NewValue(val, value.GetFormat(), value.GetRawType(), valueOptionsOf(value)...)
 		}
 	} else if value, ok := val.(Value); ok {
 		r.m[key] = value
@@ -373,54 +424,40 @@ func (r *row) GetValueAtIndex(index int) (Value, bool) {
 	return r.GetValue(key)
 }
 
+// GetValueAtPath returns the value addressed by the path, if the path contains wildcards the first value found is
+// returned.
 func (r *row) GetValueAtPath(path string) (Value, bool) {
-	keys := strings.Split(path, ".")
-
-	var row Row = r
-	for _, key := range keys {
-		value, exist := row.GetValue(key)
-		if !exist {
-			return nil, false
-		}
+	targets, err := resolve(r, path, false)
+	if err != nil {
+		return nil, false
+	}
 
-		if cast, ok := value.(Row); ok {
-			row = cast
-		} else {
+	for _, target := range targets {
+		if value, exist := target.value(); exist {
 			return value, true
 		}
 	}
 
-	return row, true
+	return nil, false
 }
 
+// FindValuesAtPath returns every value addressed by the path, a key applied to an array is applied to each row
+// contained in the array.
 func (r *row) FindValuesAtPath(path string) ([]Value, bool) {
-	keys := strings.SplitN(path, ".", 2) //nolint:gomnd
-
-	value, exist := r.GetValue(keys[0])
-	if !exist {
+	targets, err := resolve(r, path, false)
+	if err != nil {
 		return nil, false
 	}
 
-	if cast, ok := value.(Row); ok {
-		return cast.FindValuesAtPath(keys[1])
-	}
-
 	result := []Value{}
 
-	switch typedValue := value.Raw().(type) {
-	case []interface{}:
-		for _, row := range typedValue {
-			if cast, ok := row.(Row); ok {
-				if values, exists := cast.FindValuesAtPath(keys[1]); exists {
-					result = append(result, values...)
-				}
-			}
+	for _, target := range targets {
+		if value, exist := target.value(); exist {
+			result = append(result, value)
 		}
-	default:
-		return []Value{value}, true
 	}
 
-	return result, true
+	return result, len(result) > 0
 }
 
 func (r *row) SetValue(key string, val Value) Row {
@@ -428,6 +465,8 @@ func (r *row) SetValue(key string, val Value) Row {
 		r.keys[key] = r.l.PushBack(key)
 	}
 
+	r.mark(key)
+
 	r.m[key] = val
 
 	return r
@@ -448,8 +487,72 @@ func (r *row) SetValueAtIndex(index int, val Value) Row {
 	return r.SetValue(key, val)
 }
 
-func (r *row) SetValueAtPath(path string, val Value) Row {
-	panic("not implemented")
+func (r *row) SetAtPath(path string, val interface{}) error {
+	targets, err := resolve(r, path, true)
+	if err != nil {
+		return err
+	}
+
+	if len(targets) == 0 {
+		return fmt.Errorf("%w: %s", ErrPathNotFound, path)
+	}
+
+	for _, target := range targets {
+		if err := target.set(val); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *row) SetValueAtPath(path string, val Value) error {
+	targets, err := resolve(r, path, true)
+	if err != nil {
+		return err
+	}
+
+	if len(targets) == 0 {
+		return fmt.Errorf("%w: %s", ErrPathNotFound, path)
+	}
+
+	for _, target := range targets {
+		target.setValue(val)
+	}
+
+	return nil
+}
+
+func (r *row) Delete(key string) bool {
+	e, ok := r.keys[key]
+	if !ok {
+		return false
+	}
+
+	r.l.Remove(e)
+	delete(r.keys, key)
+	delete(r.m, key)
+	delete(r.present, key)
+
+	return true
+}
+
+func (r *row) DeleteAtPath(path string) bool {
+	targets, err := resolve(r, path, false)
+	if err != nil {
+		return false
+	}
+
+	deleted := false
+
+	// delete in reverse order so that array indices remain valid
+	for i := len(targets) - 1; i >= 0; i-- {
+		if targets[i].delete() {
+			deleted = true
+		}
+	}
+
+	return deleted
 }
 
 func (r *row) IterValues() func() (string, Value, bool) {
@@ -467,33 +570,65 @@ func (r *row) IterValues() func() (string, Value, bool) {
 	}
 }
 
+// MapTo sets the fields of the struct pointed by v with the values of the row, errors are ignored (use MapToE to get
+// them).
 func (r *row) MapTo(v interface{}) {
-	t := reflect.TypeOf(v).Elem()
-
-	for i := 0; i < t.NumField(); i++ {
-		field := t.Field(i)
-
-		value, exist := r.Get(LcFirst(field.Name))
-		if exist {
-			switch val := value.(type) {
-			case int, int64, int32, int16, int8:
-				i, _ := cast.ToInt64(val)
-				reflect.ValueOf(v).Elem().FieldByName(field.Name).SetInt(i.(int64))
-			case uint, uint64, uint32, uint16, uint8:
-				i, _ := cast.ToUint64(val)
-				reflect.ValueOf(v).Elem().FieldByName(field.Name).SetUint(i.(uint64))
-			case float32, float64:
-				i, _ := cast.ToFloat64(val)
-				reflect.ValueOf(v).Elem().FieldByName(field.Name).SetFloat(i.(float64))
-			case string:
-				reflect.ValueOf(v).Elem().FieldByName(field.Name).SetString(val)
-			case bool:
-				reflect.ValueOf(v).Elem().FieldByName(field.Name).SetBool(val)
-			case []byte:
-				reflect.ValueOf(v).Elem().FieldByName(field.Name).SetBytes(val)
-			}
-		}
+	_ = r.MapToE(v)
+}
+
+// MapToE sets the fields of the struct pointed by v with the values of the row, see RowFrom for the naming of columns.
+// Fields that cannot be mapped are reported by a *MappingError, the other fields are mapped anyway.
+func (r *row) MapToE(v interface{}) error {
+	target := reflect.ValueOf(v)
+	if target.Kind() != reflect.Ptr || target.IsNil() || target.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("%w: %T", ErrUnsupportedExportType, v)
 	}
+
+	return mapRow(r, target.Elem())
+}
+
+// mark records that the column is present, only if the row has a schema (rows created by templates).
+func (r *row) mark(key string) {
+	if r.schema == nil {
+		return
+	}
+
+	if r.present == nil {
+		r.present = make(map[string]struct{})
+	}
+
+	r.present[key] = struct{}{}
+}
+
+func (r *row) Validate() error {
+	if violations := r.validate(""); len(violations) > 0 {
+		return &ValidationError{Violations: violations}
+	}
+
+	return nil
+}
+
+// acceptExtra applies the extra columns policy of the row to a column that is not declared, it returns false if the
+// column must be dropped.
+func acceptExtra(r Row, key string) (bool, error) {
+	typed, ok := r.(*row)
+	if !ok {
+		return true, nil
+	}
+
+	if typed.schema == nil {
+		return true, nil
+	}
+
+	switch typed.schema.extra {
+	case ExtraColumnsDrop:
+		return false, nil
+	case ExtraColumnsError:
+		return false, fmt.Errorf("%w: %s", ErrExtraColumn, key)
+	case ExtraColumnsKeep:
+	}
+
+	return true, nil
 }
 
 func LcFirst(str string) string {
@@ -509,7 +644,7 @@ func (r *row) MarshalJSON() (res []byte, err error) {
 
 	for e := r.l.Front(); e != nil; e = e.Next() {
 		k, _ := e.Value.(string)
-		if r.m[k].GetFormat() != Hidden {
+		if r.m[k].GetFormat() != Hidden && !omitted(r.m[k]) {
 			res = append(res, fmt.Sprintf("%q:", k)...)
 
 			var b []byte
@@ -613,7 +748,11 @@ func (r *row) parseobject(dec *json.Decoder) (err error) {
 			if err := existing.Import(value); err != nil {
 				return err
 			}
-		} else {
+
+			r.mark(key)
+		} else if accept, err := acceptExtra(r, key); err != nil {
+			return err
+		} else if accept {
 			r.keys[key] = r.l.PushBack(key)
 			r.m[key] = NewValueAuto(value)
 		}
@@ -685,97 +824,146 @@ func handledelim(t json.Token, dec *json.Decoder) (res interface{}, err error) {
 }
 
 func (r *row) GetString(key string) string {
-	result, _ := cast.ToString(r.GetOrNil(key))
+	result, _ := as[string](r.GetOrNil(key))
 
-	return result.(string)
+	return result
 }
 
 func (r *row) GetInt(key string) int {
-	result, _ := cast.ToInt(r.GetOrNil(key))
+	result, _ := as[int](r.GetOrNil(key))
 
-	return result.(int)
+	return result
 }
 
 func (r *row) GetInt64(key string) int64 {
-	result, _ := cast.ToInt64(r.GetOrNil(key))
+	result, _ := as[int64](r.GetOrNil(key))
 
-	return result.(int64)
+	return result
 }
 
 func (r *row) GetInt32(key string) int32 {
-	result, _ := cast.ToInt32(r.GetOrNil(key))
+	result, _ := as[int32](r.GetOrNil(key))
 
-	return result.(int32)
+	return result
 }
 
 func (r *row) GetInt16(key string) int16 {
-	result, _ := cast.ToInt16(r.GetOrNil(key))
+	result, _ := as[int16](r.GetOrNil(key))
 
-	return result.(int16)
+	return result
 }
 
 func (r *row) GetInt8(key string) int8 {
-	result, _ := cast.ToInt8(r.GetOrNil(key))
+	result, _ := as[int8](r.GetOrNil(key))
 
-	return result.(int8)
+	return result
 }
 
 func (r *row) GetUint(key string) uint {
-	result, _ := cast.ToUint(r.GetOrNil(key))
+	result, _ := as[uint](r.GetOrNil(key))
 
-	return result.(uint)
+	return result
 }
 
 func (r *row) GetUint64(key string) uint64 {
-	result, _ := cast.ToUint64(r.GetOrNil(key))
+	result, _ := as[uint64](r.GetOrNil(key))
 
-	return result.(uint64)
+	return result
 }
 
 func (r *row) GetUint32(key string) uint32 {
-	result, _ := cast.ToUint32(r.GetOrNil(key))
+	result, _ := as[uint32](r.GetOrNil(key))
 
-	return result.(uint32)
+	return result
 }
 
 func (r *row) GetUint16(key string) uint16 {
-	result, _ := cast.ToUint16(r.GetOrNil(key))
+	result, _ := as[uint16](r.GetOrNil(key))
 
-	return result.(uint16)
+	return result
 }
 
 func (r *row) GetUint8(key string) uint8 {
-	result, _ := cast.ToUint8(r.GetOrNil(key))
+	result, _ := as[uint8](r.GetOrNil(key))
 
-	return result.(uint8)
+	return result
 }
 
 func (r *row) GetFloat64(key string) float64 {
-	result, _ := cast.ToFloat64(r.GetOrNil(key))
+	result, _ := as[float64](r.GetOrNil(key))
 
-	return result.(float64)
+	return result
 }
 
 func (r *row) GetFloat32(key string) float32 {
-	result, _ := cast.ToFloat32(r.GetOrNil(key))
+	result, _ := as[float32](r.GetOrNil(key))
 
-	return result.(float32)
+	return result
 }
 
 func (r *row) GetBool(key string) bool {
-	result, _ := cast.ToBool(r.GetOrNil(key))
+	result, _ := as[bool](r.GetOrNil(key))
 
-	return result.(bool)
+	return result
 }
 
 func (r *row) GetBytes(key string) []byte {
-	result, _ := cast.ToBinary(r.GetOrNil(key))
+	result, _ := as[[]byte](r.GetOrNil(key))
 
-	return result.([]byte)
+	return result
 }
 
 func (r *row) GetTime(key string) time.Time {
-	result, _ := cast.ToTime(r.GetOrNil(key))
+	result, _ := as[time.Time](r.GetOrNil(key))
+
+	return result
+}
+
+// Get returns the value at the given path of the row, converted to the type T.
+// An error is returned if the path does not exist, if the value is null or if the conversion fails.
+func Get[T any](r Row, path string) (T, error) {
+	val, ok := r.GetAtPath(path)
+	if !ok {
+		var zero T
+
+		return zero, fmt.Errorf("%w: %s", ErrPathNotFound, path)
+	}
+
+	result, err := as[T](val)
+	if err != nil {
+		return result, fmt.Errorf("%w at %s", err, path)
+	}
+
+	return result, nil
+}
+
+// GetOr returns the value at the given path of the row, converted to the type T,
+// or the default value if the path does not exist, if the value is null or if the conversion fails.
+func GetOr[T any](r Row, path string, def T) T {
+	result, err := Get[T](r, path)
+	if err != nil {
+		return def
+	}
+
+	return result
+}
+
+func as[T any](val interface{}) (T, error) {
+	var zero T
+
+	if val == nil {
+		return zero, ErrNullValue
+	}
+
+	result, err := cast.To(zero, val)
+	if err != nil {
+		return zero, fmt.Errorf("%w", err)
+	}
+
+	typed, ok := result.(T)
+	if !ok {
+		return zero, fmt.Errorf("%w: %#v to %T", cast.ErrUnableToCast, val, zero)
+	}
 
-	return result.(time.Time)
+	return typed, nil
 }
@@ -24,6 +24,7 @@
 	"testing"
 	"time"
 
+	"github.com/cgi-fr/jsonline/pkg/cast"
 	"github.com/cgi-fr/jsonline/pkg/jsonline"
 	"github.com/stretchr/testify/assert"
 )
@@ -263,3 +264,194 @@ func TestPathArrays(t *testing.T) {
 
 	assert.Equal(t, expected1, actual1)
 }
+
+func TestSetAtPath(t *testing.T) {
+	template := jsonline.NewTemplate().
+		WithString("name").
+		WithRow("customer", jsonline.NewTemplate().
+			WithRow("address", jsonline.NewTemplate().
+				WithMappedNumeric("zip", int64(0))))
+
+	row := template.CreateRowEmpty()
+
+	assert.NoError(t, row.SetAtPath("customer.address.zip", "75001"))
+	assert.Equal(t, int64(75001), row.GetAtPathOrNil("customer.address.zip"))
+
+	assert.NoError(t, row.SetAtPath("customer.address.city", "Paris"))
+	assert.NoError(t, row.SetAtPath("shipping.address.city", "Lyon"))
+	assert.Equal(t,
+		`{"name":null,"customer":{"address":{"zip":75001,"city":"Paris"}},"shipping":{"address":{"city":"Lyon"}}}`,
+		row.String())
+
+	assert.ErrorIs(t, row.SetAtPath("customer.address.zip.code", 0), jsonline.ErrNotARow)
+}
+
+func TestSetValueAtPath(t *testing.T) {
+	row := jsonline.NewRow()
+
+	assert.NoError(t, row.SetValueAtPath("root.sub", jsonline.NewValueDateTime(time.Date(2021, time.September, 24, 21, 21, 0, 0, time.UTC)))) //nolint:lll
+	assert.NoError(t, row.SetValueAtPath("root.other", jsonline.NewValueString(42)))
+
+	assert.Equal(t, `{"root":{"sub":"2021-09-24T21:21:00Z","other":"42"}}`, row.String())
+}
+
+func TestImportAtPathCreatesRows(t *testing.T) {
+	row := jsonline.NewTemplate().WithAuto("customer").CreateRowEmpty()
+
+	assert.NoError(t, row.ImportAtPath("customer.address.zip", "75001"))
+	assert.Equal(t, `{"customer":{"address":{"zip":"75001"}}}`, row.String())
+}
+
+func TestSetAtPathFailureLeavesRowUnchanged(t *testing.T) {
+	row, err := jsonline.NewTemplate().
+		WithArrayOfRows("items", jsonline.NewTemplate()).
+		CreateRow([]byte(`{"name":"Alice","items":[{"x":null},{"x":"y"}]}`))
+	assert.NoError(t, err)
+
+	assert.ErrorIs(t, row.SetAtPath("orders[0].sku", "x"), jsonline.ErrPathNotFound)
+	assert.ErrorIs(t, row.SetAtPath("customer.address[*]", "x"), jsonline.ErrPathNotFound)
+	assert.ErrorIs(t, row.SetValueAtPath("orders.lines[0]", jsonline.NewValueAuto("x")), jsonline.ErrPathNotFound)
+	assert.ErrorIs(t, row.ImportAtPath("orders.lines[-1].sku", "x"), jsonline.ErrPathNotFound)
+	assert.ErrorIs(t, row.SetAtPath("items[*].x.y", "x"), jsonline.ErrNotARow)
+	assert.Equal(t, `{"items":[{"x":null},{"x":"y"}],"name":"Alice"}`, row.String())
+}
+
+func TestDeleteAtPath(t *testing.T) {
+	row := jsonline.NewRow()
+	assert.NoError(t, row.SetAtPath("a.b", 1))
+	assert.NoError(t, row.SetAtPath("a.c", 2))
+	assert.NoError(t, row.SetAtPath("d", 3))
+
+	assert.True(t, row.DeleteAtPath("a.b"))
+	assert.False(t, row.DeleteAtPath("a.b"))
+	assert.False(t, row.DeleteAtPath("d.e"))
+	assert.True(t, row.Delete("d"))
+
+	assert.Equal(t, `{"a":{"c":2}}`, row.String())
+	assert.Equal(t, 1, row.Len())
+}
+
+func TestPathGrammar(t *testing.T) {
+	row, err := jsonline.NewImporter(strings.NewReader(
+		`{"orders":[{"sku":"A","amount":1},{"sku":"B","amount":2},{"sku":"C","amount":3}],"meta":{"e-mail.pro":"a@b.c"},"matrix":[[1,2],[3,4]]}`, //nolint:lll
+	)).ReadOne()
+	assert.NoError(t, err)
+
+	testdatas := []struct {
+		path     string
+		expected interface{}
+		exists   bool
+	}{
+		{"orders[2].amount", json.Number("3"), true},
+		{"orders[-1].sku", "C", true},
+		{"orders[-3].sku", "A", true},
+		{"orders[3].sku", nil, false},
+		{"orders[*].sku", "A", true},
+		{`meta."e-mail.pro"`, "a@b.c", true},
+		{`meta["e-mail.pro"]`, "a@b.c", true},
+		{`meta.*`, "a@b.c", true},
+		{"matrix[1][0]", json.Number("3"), true},
+		{"orders[x]", nil, false},
+		{`meta."unterminated`, nil, false},
+	}
+
+	for _, td := range testdatas {
+		t.Run(td.path, func(t *testing.T) {
+			value, exists := row.GetAtPath(td.path)
+			assert.Equal(t, td.exists, exists)
+			assert.Equal(t, td.expected, value)
+		})
+	}
+
+	values, exists := row.FindValuesAtPath("orders[*].sku")
+	assert.True(t, exists)
+	assert.Len(t, values, 3)
+
+	values, exists = row.FindValuesAtPath("matrix[*][-1]")
+	assert.True(t, exists)
+	assert.Equal(t, json.Number("2"), values[0].Raw())
+	assert.Equal(t, json.Number("4"), values[1].Raw())
+}
+
+func TestPathWriteArrays(t *testing.T) {
+	row, err := jsonline.NewImporter(strings.NewReader(
+		`{"orders":[{"sku":"A","amount":1},{"sku":"B","amount":2}],"tags":["x","y","z"]}`,
+	)).ReadOne()
+	assert.NoError(t, err)
+
+	assert.NoError(t, row.SetAtPath("orders[*].currency", "EUR"))
+	assert.NoError(t, row.SetAtPath("orders[-1].amount", 5))
+	assert.NoError(t, row.SetAtPath("tags[0]", "w"))
+	assert.ErrorIs(t, row.SetAtPath("orders[2].amount", 5), jsonline.ErrPathNotFound)
+	assert.ErrorIs(t, row.SetAtPath("orders[a].amount", 5), jsonline.ErrInvalidPath)
+
+	assert.True(t, row.DeleteAtPath("orders[0].sku"))
+	assert.True(t, row.DeleteAtPath("tags[1]"))
+
+	assert.Equal(t,
+		`{"orders":[{"amount":1,"currency":"EUR"},{"sku":"B","amount":5,"currency":"EUR"}],"tags":["w","z"]}`,
+		row.String())
+
+	assert.True(t, row.DeleteAtPath("tags[*]"))
+	assert.Equal(t, `[]`, fmt.Sprint(row.GetOrNil("tags")))
+}
+
+func TestGenericAccessors(t *testing.T) {
+	row, err := jsonline.NewImporter(strings.NewReader(
+		`{"id":"12","name":"Alice","birth":"2000-01-02T00:00:00Z","address":{"zip":75001},"score":"n/a","note":null}`,
+	)).ReadOne()
+	assert.NoError(t, err)
+
+	id, err := jsonline.Get[int](row, "id")
+	assert.NoError(t, err)
+	assert.Equal(t, 12, id)
+
+	zip, err := jsonline.Get[string](row, "address.zip")
+	assert.NoError(t, err)
+	assert.Equal(t, "75001", zip)
+
+	birth, err := jsonline.Get[time.Time](row, "birth")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2000, time.January, 2, 0, 0, 0, 0, time.UTC), birth)
+
+	_, err = jsonline.Get[int](row, "score")
+	assert.ErrorIs(t, err, cast.ErrUnableToCast)
+
+	_, err = jsonline.Get[int](row, "note")
+	assert.ErrorIs(t, err, jsonline.ErrNullValue)
+
+	_, err = jsonline.Get[int](row, "address.city")
+	assert.ErrorIs(t, err, jsonline.ErrPathNotFound)
+
+	assert.Equal(t, "Alice", jsonline.GetOr(row, "name", "unknown"))
+	assert.Equal(t, -1, jsonline.GetOr(row, "score", -1))
+	assert.Equal(t, -1, jsonline.GetOr(row, "note", -1))
+	assert.Equal(t, "unknown", jsonline.GetOr(row, "address.city", "unknown"))
+
+	assert.NotPanics(t, func() {
+		assert.Equal(t, 0, row.GetInt("score"))
+		assert.Equal(t, "", row.GetString("note"))
+		assert.Equal(t, time.Time{}, row.GetTime("missing"))
+	})
+}
+
+func TestSetAtPathRawType(t *testing.T) {
+	template := jsonline.NewTemplate().
+		WithMappedNumeric("n", int64(0)).
+		WithArray("values", jsonline.Numeric, int64(0)).
+		WithRow("sub", jsonline.NewTemplate().WithMappedNumeric("n", int64(0)))
+
+	row, err := template.CreateRow([]byte(`{"n":1,"values":[1,2],"sub":{"n":1}}`))
+	assert.NoError(t, err)
+
+	assert.ErrorIs(t, row.SetAtPath("n", "abc"), jsonline.ErrUnsupportedImportType)
+	assert.ErrorIs(t, row.SetAtPath("values[0]", "abc"), jsonline.ErrUnsupportedImportType)
+	assert.ErrorIs(t, row.SetAtPath("sub.n", "abc"), jsonline.ErrUnsupportedImportType)
+	assert.Equal(t, `{"n":1,"values":[1,2],"sub":{"n":1}}`, row.String())
+
+	assert.NoError(t, row.SetAtPath("n", "42"))
+	assert.NoError(t, row.SetAtPath("values[*]", 3))
+	assert.NoError(t, row.SetAtPath("sub.n", nil))
+	assert.Equal(t, int64(42), row.GetOrNil("n"))
+	assert.Equal(t, `{"n":42,"values":[3,3],"sub":{"n":null}}`, row.String())
+}
@@ -0,0 +1,90 @@
+// Copyright (C) 2022 CGI France
+//
+// This file is part of the jsonline library.
+//
+// The jsonline library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The jsonline library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with the jsonline library.  If not, see <http://www.gnu.org/licenses/>.
+//
+// Linking this library statically or dynamically with other modules is
+// making a combined work based on this library.  Thus, the terms and
+// conditions of the GNU General Public License cover the whole
+// combination.
+//
+// As a special exception, the copyright holders of this library give you
+// permission to link this library with independent modules to produce an
+// executable, regardless of the license terms of these independent
+// modules, and to copy and distribute the resulting executable under
+// terms of your choice, provided that you also meet, for each linked
+// independent module, the terms and conditions of the license of that
+// module.  An independent module is a module which is not derived from
+// or based on this library.  If you modify this library, you may extend
+// this exception to your version of the library, but you are not
+// obligated to do so.  If you do not wish to do so, delete this
+// exception statement from your version.
+
+package jsonline
+
+import (
+	"fmt"
+	"net/netip"
+
+	"github.com/cgi-fr/jsonline/pkg/cast"
+)
+
+// canonicalString validates the value against a semantic format and returns its canonical representation (lowercase
+// UUID, compressed IPv6, lowercase domain).
+//
+//nolint:cyclop
+func canonicalString(val interface{}, f Format) (string, error) {
+	var (
+		result interface{}
+		err    error
+	)
+
+	switch f { //nolint:exhaustive
+	case UUID:
+		result, err = cast.ToUUID(val)
+	case IPv4, IPv6:
+		result, err = cast.ToAddr(val)
+		if addr, ok := result.(netip.Addr); ok && err == nil {
+			if f == IPv4 && addr.Is4In6() {
+				addr = addr.Unmap()
+			}
+
+			if addr.Is4() != (f == IPv4) {
+				err = fmt.Errorf("%w: %v is not an %s address", cast.ErrUnableToCastToIP, val, formatName(f))
+			}
+
+			result = addr
+		}
+	case CIDR:
+		result, err = cast.ToPrefix(val)
+	case URL:
+		result, err = cast.ToURL(val)
+	case Email:
+		result, err = cast.ToMailAddress(val)
+	default:
+		err = fmt.Errorf("%w: %#v", ErrUnsupportedFormat, f)
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	str, err := cast.ToString(result)
+	if err != nil {
+		return "", fmt.Errorf("%w", err)
+	}
+
+	return str.(string), nil
+}
@@ -35,8 +35,21 @@
 package jsonline
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
+
+	"github.com/cgi-fr/jsonline/pkg/cast"
+	"gopkg.in/yaml.v3"
+)
+
+// ExtraColumnsPolicy defines how columns that are not declared in a template are handled.
+type ExtraColumnsPolicy int8
+
+const (
+	ExtraColumnsKeep  ExtraColumnsPolicy = iota // Extra columns are kept at the end of the row (default).
+	ExtraColumnsDrop                            // Extra columns are silently removed.
+	ExtraColumnsError                           // Extra columns are rejected with an error.
 )
 
 type Template interface {
@@ -50,6 +63,8 @@ type Template interface {
 	WithAuto(name string) Template
 	WithHidden(name string) Template
 	WithRow(name string, row Template) Template
+	WithArray(name string, format Format, rawtype RawType, opts ...ValueOption) Template
+	WithArrayOfRows(name string, row Template) Template
 
 	WithMappedString(name string, rawtype RawType) Template
 	WithMappedNumeric(name string, rawtype RawType) Template
@@ -60,15 +75,38 @@ type Template interface {
 	WithMappedTimestamp(name string, rawtype RawType) Template
 	WithMappedAuto(name string, rawtype RawType) Template
 
-	With(name string, format Format, rawtype RawType) Template
+	With(name string, format Format, rawtype RawType, opts ...ValueOption) Template
+	WithExtraColumns(policy ExtraColumnsPolicy) Template
+	WithConstraints(name string, constraints ...Constraint) Template
+	WithDefault(name string, def DefaultValue) Template
+
+	Columns() []Column
+	Column(name string) (Column, bool)
 
 	CreateRow(interface{}) (Row, error)
 	CreateRowEmpty() Row
 
+	json.Marshaler
+	json.Unmarshaler
+	yaml.Marshaler
+	yaml.Unmarshaler
+
 	GetExporter(io.Writer) Exporter
 	GetImporter(io.Reader) Importer
 }
 
+// Column describes a column declared in a template.
+type Column struct {
+	Name        string
+	Format      Format
+	RawType     RawType
+	Options     []ValueOption
+	Array       bool         // the column is an array, Format, RawType and Options apply to its elements
+	Template    Template     // copy of the template of a sub row, of the rows of an array or of an embedded JSON object
+	Constraints []Constraint // constraints in order of declaration
+	Default     DefaultValue // nil if the column has no default value
+}
+
 type template struct {
 	empty Row
 }
@@ -134,11 +172,24 @@ func (t *template) WithHidden(name string) Template {
 }
 
 func (t *template) WithRow(name string, rowt Template) Template {
+	t.schema() // the presence of the sub row is tracked, sub rows missing from the input are not validated
 	t.empty.SetValue(name, rowt.CreateRowEmpty())
 
 	return t
 }
 
+func (t *template) WithArray(name string, format Format, rawtype RawType, opts ...ValueOption) Template {
+	t.empty.SetValue(name, newArray(format, rawtype, opts...))
+
+	return t
+}
+
+func (t *template) WithArrayOfRows(name string, rowt Template) Template {
+	t.empty.SetValue(name, newArrayOfRows(rowt))
+
+	return t
+}
+
 func (t *template) WithMappedString(name string, rawtype RawType) Template {
 	t.empty.SetValue(name, NewValue(nil, String, rawtype))
 
@@ -187,23 +238,107 @@ func (t *template) WithMappedAuto(name string, rawtype RawType) Template {
 	return t
 }
 
-func (t *template) With(name string, format Format, rawtype RawType) Template {
-	t.empty.SetValue(name, NewValue(nil, format, rawtype))
+func (t *template) With(name string, format Format, rawtype RawType, opts ...ValueOption) Template {
+	t.empty.SetValue(name, NewValue(nil, format, rawtype, opts...))
 
 	return t
 }
 
-//nolint:cyclop
+func (t *template) WithExtraColumns(policy ExtraColumnsPolicy) Template {
+	t.schema().extra = policy
+
+	return t
+}
+
+func (t *template) WithConstraints(name string, constraints ...Constraint) Template {
+	s := t.schema()
+
+	if s.constraints == nil {
+		s.constraints = make(map[string][]Constraint)
+	}
+
+	if _, exist := s.constraints[name]; !exist {
+		s.constrained = append(s.constrained, name)
+	}
+
+	s.constraints[name] = append(s.constraints[name], constraints...)
+
+	return t
+}
+
+func (t *template) WithDefault(name string, def DefaultValue) Template {
+	s := t.schema()
+
+	if s.defaults == nil {
+		s.defaults = make(map[string]DefaultValue)
+	}
+
+	if _, exist := s.defaults[name]; !exist {
+		s.defaulted = append(s.defaulted, name)
+	}
+
+	s.defaults[name] = def
+
+	return t
+}
+
+func (t *template) schema() *schema {
+	r, _ := t.empty.(*row)
+
+	if r.schema == nil {
+		r.schema = &schema{
+			extra:       ExtraColumnsKeep,
+			constraints: nil,
+			constrained: nil,
+			defaults:    nil,
+			defaulted:   nil,
+		}
+	}
+
+	return r.schema
+}
+
 func (t *template) CreateRow(v interface{}) (Row, error) {
 	result := CloneRow(t.empty)
 
+	if err := fill(result, v); err != nil {
+		return nil, err
+	}
+
+	if err := applyDefaults(result); err != nil {
+		return nil, err
+	}
+
+	if err := result.Validate(); err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+// fill sets the values of the row, existing columns keep their format and rawtype.
+//
+//nolint:cyclop,funlen,gocognit
+func fill(result Row, v interface{}) error {
 	switch values := v.(type) {
 	case []interface{}:
 		for i, val := range values {
 			target, ok := result.GetValueAtIndex(i)
 			if ok && target != nil {
-				target = NewValue(val, target.GetFormat(), target.GetRawType())
+				var err error
+				if target, err = assign(target, val); err != nil {
+					return err
+				}
 			} else {
+				accept, err := acceptExtra(result, fmt.Sprintf("#%d", i))
+				if err != nil {
+					return err
+				}
+
+				if !accept {
+					continue
+				}
+
 				target = NewValueAuto(val)
 			}
 
@@ -213,8 +348,20 @@ func (t *template) CreateRow(v interface{}) (Row, error) {
 		for key, val := range values {
 			target, ok := result.GetValue(key)
 			if ok && target != nil {
-				target = NewValue(val, target.GetFormat(), target.GetRawType())
+				var err error
+				if target, err = assign(target, val); err != nil {
+					return err
+				}
 			} else {
+				accept, err := acceptExtra(result, key)
+				if err != nil {
+					return err
+				}
+
+				if !accept {
+					continue
+				}
+
 				target = NewValueAuto(val)
 			}
 
@@ -226,8 +373,20 @@ func (t *template) CreateRow(v interface{}) (Row, error) {
 		for key, val, ok := iter(); ok; key, val, ok = iter() {
 			target, ok := result.GetValue(key)
 			if ok && target != nil {
-				target = NewValue(val.Raw(), target.GetFormat(), target.GetRawType())
+				var err error
+				if target, err = assign(target, rawOf(val, target)); err != nil {
+					return err
+				}
 			} else {
+				accept, err := acceptExtra(result, key)
+				if err != nil {
+					return err
+				}
+
+				if !accept {
+					continue
+				}
+
 				target = NewValueAuto(val.Raw())
 			}
 
@@ -236,19 +395,80 @@ func (t *template) CreateRow(v interface{}) (Row, error) {
 
 	case []byte:
 		if err := result.UnmarshalJSON(values); err != nil {
-			return nil, fmt.Errorf("%w", err)
+			return fmt.Errorf("%w", err)
 		}
 
 	case string:
 		if err := result.UnmarshalJSON([]byte(values)); err != nil {
-			return nil, fmt.Errorf("%w", err)
+			return fmt.Errorf("%w", err)
 		}
 
 	default:
-		return nil, fmt.Errorf("%w", ErrUnsupportedImportType)
+		return fmt.Errorf("%w", ErrUnsupportedImportType)
 	}
 
-	return result, nil
+	return nil
+}
+
+// assign returns a new value holding val with the format and rawtype of target, sub rows and arrays declared by target
+// are filled with val.
+func assign(target Value, val interface{}) (Value, error) {
+	switch typed := target.(type) {
+	case Row:
+		result := CloneRow(typed)
+
+		if val == nil {
+			return result, nil
+		}
+
+		if err := fill(result, val); err != nil {
+			return nil, err
+		}
+
+		return result, nil
+	case *array:
+		result := typed.clone()
+
+		if err := result.assign(val); err != nil {
+			return nil, err
+		}
+
+		return result, nil
+	default:
+		return NewValue(val, target.GetFormat(), target.GetRawType(), valueOptionsOf(target)...), nil
+	}
+}
+
+// rawOf returns the raw content of a value, rows are kept as is to preserve the order of keys. Timestamps are converted
+// to times when the target has another temporal format, and to counts of units otherwise, so that the unit of the
+// timestamp is not lost.
+func rawOf(v Value, target Value) interface{} {
+	if row, ok := v.(Row); ok {
+		return row
+	}
+
+	if row, ok := v.Raw().(Row); ok {
+		return row
+	}
+
+	if unit, ok := epochUnit(v.GetFormat()); ok && v.GetFormat() != target.GetFormat() {
+		convert := cast.ToTimestampUnit
+		if isTemporal(target.GetFormat()) {
+			convert = cast.ToTimeUnit
+		}
+
+		if raw, err := convert(v.Raw(), unit); err == nil {
+			return raw
+		}
+	}
+
+	return v.Raw()
+}
+
+func isTemporal(f Format) bool {
+	_, isTimestamp := epochUnit(f)
+
+	return isTimestamp || f == Date || f == DateTime
 }
 
 func (t *template) CreateRowEmpty() Row {
@@ -262,3 +482,104 @@ func (t *template) GetExporter(w io.Writer) Exporter {
 func (t *template) GetImporter(r io.Reader) Importer {
 	return NewImporter(r).WithTemplate(t)
 }
+
+func (t *template) Columns() []Column {
+	result := make([]Column, 0, t.empty.Len())
+
+	iter := t.empty.IterValues()
+	for key, val, ok := iter(); ok; key, val, ok = iter() {
+		result = append(result, t.column(key, val))
+	}
+
+	return result
+}
+
+func (t *template) Column(name string) (Column, bool) {
+	val, ok := t.empty.GetValue(name)
+	if !ok {
+		return Column{}, false //nolint:exhaustivestruct
+	}
+
+	return t.column(name, val), true
+}
+
+func (t *template) column(name string, val Value) Column {
+	result := Column{
+		Name:        name,
+		Format:      val.GetFormat(),
+		RawType:     val.GetRawType(),
+		Options:     valueOptionsOf(val),
+		Array:       false,
+		Template:    nil,
+		Constraints: nil,
+		Default:     nil,
+	}
+
+	switch v := val.(type) {
+	case Row:
+		result.Template = &template{empty: CloneRow(v)}
+	case *array:
+		result.Array = true
+
+		if elem, ok := v.empty().(Row); ok {
+			result.Template = &template{empty: elem}
+		}
+	}
+
+	if o := newOptions(result.Options); o != nil && o.embedded != nil {
+		result.Template = o.embedded
+	}
+
+	if r, ok := t.empty.(*row); ok && r.schema != nil {
+		result.Constraints = append(result.Constraints, r.schema.constraints[name]...)
+		result.Default = r.schema.defaults[name]
+	}
+
+	return result
+}
+
+// MarshalJSON writes the template as a row definition.
+func (t *template) MarshalJSON() ([]byte, error) {
+	result, err := json.Marshal(NewRowDefinition(t))
+	if err != nil {
+		return nil, fmt.Errorf("%w", err)
+	}
+
+	return result, nil
+}
+
+// UnmarshalJSON reads the template from a row definition, the input template of the definition is used.
+func (t *template) UnmarshalJSON(data []byte) error {
+	def := &RowDefinition{} //nolint:exhaustivestruct
+	if err := json.Unmarshal(data, def); err != nil {
+		return fmt.Errorf("%w", err)
+	}
+
+	return t.load(def)
+}
+
+// MarshalYAML writes the template as a row definition.
+func (t *template) MarshalYAML() (interface{}, error) {
+	return NewRowDefinition(t), nil
+}
+
+// UnmarshalYAML reads the template from a row definition, the input template of the definition is used.
+func (t *template) UnmarshalYAML(value *yaml.Node) error {
+	def := &RowDefinition{} //nolint:exhaustivestruct
+	if err := value.Decode(def); err != nil {
+		return fmt.Errorf("%w", err)
+	}
+
+	return t.load(def)
+}
+
+func (t *template) load(def *RowDefinition) error {
+	ti, _, err := def.Templates()
+	if err != nil {
+		return err
+	}
+
+	t.empty = ti.(*template).empty //nolint:forcetypeassert
+
+	return nil
+}
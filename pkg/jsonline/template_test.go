@@ -18,9 +18,17 @@
 package jsonline_test
 
 import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/netip"
 	"os"
+	"regexp"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/cgi-fr/jsonline/pkg/cast"
 	"github.com/cgi-fr/jsonline/pkg/jsonline"
 	"github.com/stretchr/testify/assert"
 )
@@ -205,3 +213,675 @@ func TestChaining(t *testing.T) {
 
 	assert.Equal(t, row2.String(), `{"input":2}`)
 }
+
+func TestTemplateWithArray(t *testing.T) {
+	template := jsonline.NewTemplate().
+		WithString("id").
+		WithArray("scores", jsonline.Numeric, int64(0)).
+		WithArrayOfRows("lines", jsonline.NewTemplate().
+			WithString("sku").
+			WithMappedNumeric("qty", int64(0)))
+
+	row := template.CreateRowEmpty()
+	assert.Equal(t, `{"id":null,"scores":null,"lines":null}`, row.String())
+
+	err := row.UnmarshalJSON([]byte(`{"lines":[{"qty":"2","sku":"A"},{"sku":"B","extra":true}],"scores":["1",2],"id":1}`))
+	assert.NoError(t, err)
+	assert.Equal(t,
+		`{"id":"1","scores":[1,2],"lines":[{"sku":"A","qty":2},{"sku":"B","qty":null,"extra":true}]}`,
+		row.String())
+	assert.Equal(t, []interface{}{int64(1), int64(2)}, row.GetOrNil("scores"))
+	assert.Equal(t, int64(2), row.GetAtPathOrNil("lines[0].qty"))
+
+	err = row.UnmarshalJSON([]byte(`{"scores":"1"}`))
+	assert.ErrorIs(t, err, jsonline.ErrUnsupportedImportType)
+
+	err = row.UnmarshalJSON([]byte(`{"scores":["a"]}`))
+	assert.Error(t, err)
+}
+
+func TestTemplateCreateRowWithArray(t *testing.T) {
+	template := jsonline.NewTemplate().
+		WithArray("dates", jsonline.Date, nil).
+		WithArrayOfRows("lines", jsonline.NewTemplate().WithString("sku"))
+
+	row, err := template.CreateRow(map[string]interface{}{
+		"dates": []time.Time{time.Date(2021, time.September, 24, 21, 21, 0, 0, time.UTC)},
+		"lines": []interface{}{map[string]interface{}{"sku": 42}},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, `{"dates":["2021-09-24"],"lines":[{"sku":"42"}]}`, row.String())
+
+	assert.NoError(t, row.SetAtPath("lines[0].sku", 7))
+	assert.NoError(t, row.SetAtPath("dates[0]", "2022-01-01"))
+	assert.Equal(t, `{"dates":["2022-01-01"],"lines":[{"sku":"7"}]}`, row.String())
+
+	_, err = template.CreateRow(map[string]interface{}{"lines": "not an array"})
+	assert.ErrorIs(t, err, jsonline.ErrUnsupportedImportType)
+}
+
+func TestTemplateExtraColumns(t *testing.T) {
+	sub := jsonline.NewTemplate().WithString("first").WithExtraColumns(jsonline.ExtraColumnsDrop)
+
+	template := jsonline.NewTemplate().
+		WithString("string").
+		WithRow("row", sub)
+
+	row, err := template.CreateRow(map[string]interface{}{"string": "value", "extra": 1, "row": map[string]interface{}{"first": 1, "second": 2}}) //nolint:lll
+	assert.NoError(t, err)
+	assert.Equal(t, `{"string":"value","row":{"first":"1"},"extra":1}`, row.String())
+
+	template.WithExtraColumns(jsonline.ExtraColumnsDrop)
+
+	row, err = template.CreateRow(map[string]interface{}{"string": "value", "extra": 1})
+	assert.NoError(t, err)
+	assert.Equal(t, `{"string":"value","row":{"first":null}}`, row.String())
+
+	row = template.CreateRowEmpty()
+	assert.NoError(t, row.UnmarshalJSON([]byte(`{"extra":1,"string":"value","row":{"second":2}}`)))
+	assert.Equal(t, `{"string":"value","row":{"first":null}}`, row.String())
+
+	template.WithExtraColumns(jsonline.ExtraColumnsError)
+
+	_, err = template.CreateRow(map[string]interface{}{"string": "value", "extra": 1})
+	assert.ErrorIs(t, err, jsonline.ErrExtraColumn)
+
+	_, err = template.CreateRow([]interface{}{"value", nil, "extra"})
+	assert.ErrorIs(t, err, jsonline.ErrExtraColumn)
+
+	row = template.CreateRowEmpty()
+	assert.ErrorIs(t, row.UnmarshalJSON([]byte(`{"string":"value","extra":1}`)), jsonline.ErrExtraColumn)
+	assert.ErrorIs(t, row.ImportAtKey("extra", 1), jsonline.ErrExtraColumn)
+}
+
+func TestTemplateConstraints(t *testing.T) {
+	template := jsonline.NewTemplate().
+		WithString("id").
+		WithString("name").
+		WithConstraints("id", jsonline.Required(), jsonline.NotNull()).
+		WithConstraints("name", jsonline.NotNull()).
+		WithArrayOfRows("lines", jsonline.NewTemplate().
+			WithString("sku").
+			WithConstraints("sku", jsonline.Required()))
+
+	row := template.CreateRowEmpty()
+	assert.NoError(t, row.UnmarshalJSON([]byte(`{"id":"1","name":"a","lines":[{"sku":"A"}]}`)))
+	assert.NoError(t, row.Validate())
+
+	row = template.CreateRowEmpty()
+	assert.NoError(t, row.UnmarshalJSON([]byte(`{"name":null,"lines":[{"sku":"A"},{}]}`)))
+
+	err := row.Validate()
+	assert.ErrorIs(t, err, jsonline.ErrConstraintViolation)
+
+	var validationError *jsonline.ValidationError
+
+	assert.ErrorAs(t, err, &validationError)
+	assert.Equal(t, []jsonline.Violation{
+		{Path: "id", Constraint: "required", Value: nil, Err: jsonline.ErrMissingColumn},
+		{Path: "id", Constraint: "nullable", Value: nil, Err: jsonline.ErrNullValue},
+		{Path: "name", Constraint: "nullable", Value: nil, Err: jsonline.ErrNullValue},
+		{Path: "lines[1].sku", Constraint: "required", Value: nil, Err: jsonline.ErrMissingColumn},
+	}, validationError.Violations)
+
+	_, err = template.CreateRow(map[string]interface{}{"id": 1})
+	assert.EqualError(t, err, "constraint violation: name: value is null")
+
+	_, err = jsonline.NewImporter(strings.NewReader(`{"id":1}`)).WithTemplate(template).ReadOne()
+	assert.ErrorIs(t, err, jsonline.ErrConstraintViolation)
+}
+
+func TestTemplateSubRowConstraints(t *testing.T) {
+	template := jsonline.NewTemplate().
+		WithRow("a", jsonline.NewTemplate().
+			WithString("b").
+			WithConstraints("b", jsonline.Required())).
+		WithConstraints("a", jsonline.Required())
+
+	_, err := template.CreateRow([]byte(`{"a":{"b":"x"}}`))
+	assert.NoError(t, err)
+
+	_, err = template.CreateRow([]byte(`{"a":{}}`))
+	assert.EqualError(t, err, "constraint violation: a.b: column is missing")
+
+	// the missing sub row is only reported once
+	_, err = template.CreateRow([]byte(`{}`))
+	assert.EqualError(t, err, "constraint violation: a: column is missing")
+
+	optional := jsonline.NewTemplate().
+		WithRow("a", jsonline.NewTemplate().
+			WithString("b").
+			WithConstraints("b", jsonline.Required()))
+
+	_, err = optional.CreateRow([]byte(`{}`))
+	assert.NoError(t, err)
+}
+
+func TestTemplateValueConstraints(t *testing.T) {
+	template := jsonline.NewTemplate().
+		WithString("status").
+		WithString("code").
+		WithMappedNumeric("qty", int64(0)).
+		WithDate("birth").
+		WithDateTime("created").
+		WithBinary("key").
+		WithConstraints("status", jsonline.Enum("new", "done")).
+		WithConstraints("code", jsonline.Pattern(regexp.MustCompile("^[A-Z]{3}$")), jsonline.MaxLength(3)).
+		WithConstraints("qty", jsonline.Min(1), jsonline.Max(10)).
+		WithConstraints("birth", jsonline.Min("1900-01-01")).
+		WithConstraints("created", jsonline.Max("2030-01-01T00:00:00Z")).
+		WithConstraints("key", jsonline.MinLength(4))
+
+	_, err := template.CreateRow(map[string]interface{}{
+		"status": "new", "code": "ABC", "qty": "10", "birth": "1990-05-01",
+		"created": "2021-09-24T21:21:21Z", "key": []byte("1234"),
+	})
+	assert.NoError(t, err)
+
+	_, err = template.CreateRow(map[string]interface{}{"status": nil, "code": nil})
+	assert.NoError(t, err)
+
+	_, err = template.CreateRow(map[string]interface{}{
+		"status": "old", "code": "abcd", "qty": 0, "birth": "1890-05-01",
+		"created": "2031-09-24T21:21:21Z", "key": []byte("123"),
+	})
+
+	var validationError *jsonline.ValidationError
+
+	assert.ErrorAs(t, err, &validationError)
+
+	expected := []struct {
+		path       string
+		constraint string
+		value      interface{}
+		err        error
+	}{
+		{"status", "enum", "old", jsonline.ErrNotAllowed},
+		{"code", "pattern", "abcd", jsonline.ErrPatternMismatch},
+		{"code", "maxLength", "abcd", jsonline.ErrInvalidLength},
+		{"qty", "min", int64(0), jsonline.ErrOutOfRange},
+		{"birth", "min", "1890-05-01", jsonline.ErrOutOfRange},
+		{"created", "max", nil, jsonline.ErrOutOfRange},
+		{"key", "minLength", []byte("123"), jsonline.ErrInvalidLength},
+	}
+
+	if assert.Len(t, validationError.Violations, len(expected)) {
+		for i, violation := range validationError.Violations {
+			assert.Equal(t, expected[i].path, violation.Path)
+			assert.Equal(t, expected[i].constraint, violation.Constraint)
+			assert.ErrorIs(t, violation.Err, expected[i].err)
+
+			if expected[i].value != nil {
+				assert.Equal(t, expected[i].value, violation.Value)
+			}
+		}
+	}
+}
+
+func TestTemplateDefaults(t *testing.T) {
+	template := jsonline.NewTemplate().
+		WithMappedNumeric("id", int64(0)).
+		WithString("status").
+		WithDateTime("created").
+		WithDefault("id", jsonline.Sequence(10)).
+		WithDefault("status", jsonline.Literal("new")).
+		WithDefault("created", jsonline.Now()).
+		WithDefault("source", jsonline.Literal("jl")).
+		WithConstraints("status", jsonline.NotNull()).
+		WithArrayOfRows("lines", jsonline.NewTemplate().
+			WithNumeric("qty").
+			WithDefault("qty", jsonline.Literal(1)))
+
+	row, err := jsonline.NewImporter(strings.NewReader(`{"status":null,"lines":[{"qty":2},{}]}`)).
+		WithTemplate(template).ReadOne()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(10), row.GetOrNil("id"))
+	assert.Equal(t, "new", row.GetOrNil("status"))
+	assert.IsType(t, time.Time{}, row.GetOrNil("created"))
+	assert.Equal(t, "jl", row.GetOrNil("source"))
+	assert.Equal(t, 1, row.GetAtPathOrNil("lines[1].qty"))
+
+	row, err = template.CreateRow(map[string]interface{}{"id": 1, "status": "done", "created": nil})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), row.GetOrNil("id"))
+	assert.Equal(t, "done", row.GetOrNil("status"))
+	assert.IsType(t, time.Time{}, row.GetOrNil("created"))
+
+	row, err = template.CreateRow(map[string]interface{}{})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(11), row.GetOrNil("id"))
+}
+
+func TestTemplateDefaultsRequired(t *testing.T) {
+	template := jsonline.NewTemplate().
+		WithString("x").
+		WithDefault("x", jsonline.Literal("d")).
+		WithConstraints("x", jsonline.Required(), jsonline.NotNull()).
+		WithRow("sub", jsonline.NewTemplate().
+			WithDefault("y", jsonline.Literal(1)).
+			WithConstraints("y", jsonline.Required()))
+
+	row, err := template.CreateRow([]byte(`{}`))
+	assert.NoError(t, err)
+	assert.Equal(t, `{"x":"d","sub":{"y":1}}`, row.String())
+
+	row, err = template.CreateRow([]byte(`{"x":null,"sub":{}}`))
+	assert.NoError(t, err)
+	assert.Equal(t, `{"x":"d","sub":{"y":1}}`, row.String())
+	assert.NoError(t, row.Validate())
+}
+
+func TestTemplateLayouts(t *testing.T) {
+	template := jsonline.NewTemplate().
+		With("birth", jsonline.Date, nil, jsonline.Layouts("02/01/2006", "2006-01-02")).
+		With("created", jsonline.DateTime, nil, jsonline.Layouts("%d/%m/%Y %H:%M")).
+		With("updated", jsonline.DateTime, time.Time{}, jsonline.Layouts("02/01/2006 15:04")).
+		WithArray("days", jsonline.Date, nil, jsonline.Layouts("02/01/2006"))
+
+	row, err := template.CreateRow([]byte(
+		`{"birth":"1990-05-01","created":"24/09/2021 21:21","updated":"25/09/2021 10:00","days":["01/02/2021"]}`))
+	assert.NoError(t, err)
+	assert.Equal(t, "1990-05-01", row.GetOrNil("birth"))
+	assert.Equal(t, time.Date(2021, 9, 24, 21, 21, 0, 0, time.UTC), row.GetOrNil("created"))
+	assert.Equal(t, time.Date(2021, 9, 25, 10, 0, 0, 0, time.UTC), row.GetOrNil("updated"))
+	assert.Equal(t, `{"birth":"01/05/1990","created":"24/09/2021 21:21","updated":"25/09/2021 10:00","days":["01/02/2021"]}`,
+		row.String())
+
+	_, err = template.CreateRow([]byte(`{"birth":"1990/05/01"}`))
+	assert.ErrorIs(t, err, jsonline.ErrUnsupportedImportType)
+
+	column, ok := template.Column("created")
+	assert.True(t, ok)
+	assert.Equal(t, "datetime[02/01/2006 15:04]", jsonline.Descriptor(column.Format, column.RawType, column.Options...))
+
+	format, rawtype, opts, err := jsonline.ParseDescriptor("datetime[02/01/2006 15:04|2006-01-02](time.Time)")
+	assert.NoError(t, err)
+	assert.Equal(t, jsonline.DateTime, format)
+	assert.Equal(t, time.Time{}, rawtype)
+	assert.Equal(t, "datetime[02/01/2006 15:04|2006-01-02](time.Time)", jsonline.Descriptor(format, rawtype, opts...))
+}
+
+func TestTemplateTimezones(t *testing.T) {
+	utc, err := time.LoadLocation("UTC")
+	assert.NoError(t, err)
+
+	newYork, err := time.LoadLocation("America/New_York")
+	assert.NoError(t, err)
+
+	template := jsonline.NewTemplate().
+		With("created", jsonline.DateTime, nil, jsonline.Timezone(utc)).
+		With("updated", jsonline.DateTime, nil, jsonline.Timezone(utc), jsonline.AssumeTimezone(newYork)).
+		With("timestamp", jsonline.DateTime, int64(0), jsonline.Timezone(utc)).
+		With("day", jsonline.Date, nil, jsonline.Timezone(newYork))
+
+	row, err := template.CreateRow([]byte(
+		`{"created":"2021-09-24T21:21:54+02:00","updated":"2021-09-24T21:21:54","timestamp":1632511314,` +
+			`"day":"2021-09-25T02:00:00Z"}`))
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2021, 9, 24, 19, 21, 54, 0, utc), row.GetOrNil("created"))
+	assert.Equal(t, int64(1632511314), row.GetOrNil("timestamp"))
+	assert.Equal(t, "2021-09-24", row.GetOrNil("day"))
+	assert.Equal(t, `{"created":"2021-09-24T19:21:54Z","updated":"2021-09-25T01:21:54Z",`+
+		`"timestamp":"2021-09-24T19:21:54Z","day":"2021-09-24"}`, row.String())
+}
+
+func TestTemplateTimestampUnits(t *testing.T) {
+	ti := jsonline.NewTemplate().
+		With("ms", jsonline.TimestampMilli, nil).
+		With("us", jsonline.TimestampMicro, nil).
+		With("ns", jsonline.TimestampNano, int64(0)).
+		With("float", jsonline.Timestamp, time.Time{})
+	to := jsonline.NewTemplate().
+		With("ms", jsonline.DateTime, nil, jsonline.Layouts(time.RFC3339Nano), jsonline.Timezone(time.UTC)).
+		With("us", jsonline.TimestampMilli, nil).
+		With("ns", jsonline.Numeric, nil).
+		With("float", jsonline.TimestampMilli, nil)
+
+	row, err := ti.CreateRow([]byte(
+		`{"ms":1632511314123,"us":1632511314123456,"ns":1632511314123456789,"float":1632511314.5}`))
+	assert.NoError(t, err)
+	assert.Equal(t, time.Unix(1632511314, 123000000), row.GetOrNil("ms"))
+	assert.Equal(t, int64(1632511314123456789), row.GetOrNil("ns"))
+	assert.Equal(t, `{"ms":1632511314123,"us":1632511314123456,"ns":1632511314123456789,"float":1632511314}`,
+		row.String())
+
+	row, err = to.CreateRow(row)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"ms":"2021-09-24T19:21:54.123Z","us":1632511314123,"ns":1632511314123456789,"float":1632511314500}`,
+		row.String())
+}
+
+func TestTemplateDecimal(t *testing.T) {
+	template := jsonline.NewTemplate().
+		With("exact", jsonline.Decimal, nil).
+		With("even", jsonline.Decimal, nil, jsonline.Precision(18, 2)).
+		With("up", jsonline.Decimal, nil, jsonline.Precision(18, 2), jsonline.Rounding(big.ToNearestAway)).
+		With("floor", jsonline.Decimal, (*big.Rat)(nil), jsonline.Precision(5, 0), jsonline.Rounding(big.ToNegativeInf))
+
+	row, err := template.CreateRow([]byte(`{"exact":12345678901234567.891,"even":2.345,"up":"2.345","floor":-2.5}`))
+	assert.NoError(t, err)
+	assert.Equal(t, json.Number("12345678901234567.891"), row.GetOrNil("exact"))
+	assert.Equal(t, big.NewRat(-3, 1), row.GetOrNil("floor"))
+	assert.Equal(t, `{"exact":12345678901234567.891,"even":2.34,"up":2.35,"floor":-3}`, row.String())
+
+	_, err = template.CreateRow([]byte(`{"floor":123456}`))
+	assert.ErrorIs(t, err, jsonline.ErrOutOfPrecision)
+
+	column, ok := template.Column("floor")
+	assert.True(t, ok)
+	assert.Equal(t, "decimal(5,0)[floor](*big.Rat)", jsonline.Descriptor(column.Format, column.RawType, column.Options...))
+
+	format, rawtype, opts, err := jsonline.ParseDescriptor("decimal(18,2)[half_up](*big.Float)")
+	assert.NoError(t, err)
+	assert.Equal(t, jsonline.Decimal, format)
+	assert.Equal(t, (*big.Float)(nil), rawtype)
+	assert.Equal(t, "decimal(18,2)[half_up](*big.Float)", jsonline.Descriptor(format, rawtype, opts...))
+}
+
+func TestTemplateDuration(t *testing.T) {
+	template := jsonline.NewTemplate().
+		With("go", jsonline.Duration, nil).
+		With("iso", jsonline.Duration, nil, jsonline.Notation(jsonline.DurationISO8601)).
+		With("seconds", jsonline.Duration, nil, jsonline.Notation(jsonline.DurationSeconds)).
+		With("millis", jsonline.Duration, int64(0), jsonline.Notation(jsonline.DurationMillis))
+
+	row, err := template.CreateRow([]byte(`{"go":"PT1H30M","iso":"1h30m","seconds":"1h30m","millis":1500}`))
+	assert.NoError(t, err)
+	assert.Equal(t, 90*time.Minute, row.GetOrNil("go"))
+	assert.Equal(t, int64(1500), row.GetOrNil("millis"))
+	assert.Equal(t, `{"go":"1h30m0s","iso":"PT1H30M","seconds":5400,"millis":1500}`, row.String())
+
+	_, err = template.CreateRow([]byte(`{"go":"P1M"}`))
+	assert.ErrorIs(t, err, jsonline.ErrUnsupportedImportType)
+
+	column, ok := template.Column("iso")
+	assert.True(t, ok)
+	assert.Equal(t, "duration[iso8601]", jsonline.Descriptor(column.Format, column.RawType, column.Options...))
+}
+
+func TestTemplateBinaryEncodings(t *testing.T) {
+	ti := jsonline.NewTemplate().
+		With("digest", jsonline.Binary, nil, jsonline.Encoding(jsonline.Hex)).
+		With("token", jsonline.Binary, nil, jsonline.Encoding(jsonline.Base64URLRaw)).
+		With("key", jsonline.Binary, nil, jsonline.Encoding(jsonline.Base32))
+	to := jsonline.NewTemplate().
+		With("digest", jsonline.Binary, nil).
+		With("token", jsonline.Binary, nil, jsonline.Encoding(jsonline.Base64Raw)).
+		With("key", jsonline.Binary, nil, jsonline.Encoding(jsonline.Base32Raw))
+
+	row, err := ti.CreateRow([]byte(`{"digest":"68656C6C6F","token":"_-8","key":"NBSWY3DP"}`))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("hello"), row.GetOrNil("digest"))
+	assert.Equal(t, []byte{0xff, 0xef}, row.GetOrNil("token"))
+	assert.Equal(t, `{"digest":"68656c6c6f","token":"_-8","key":"NBSWY3DP"}`, row.String())
+
+	row, err = to.CreateRow(row)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"digest":"aGVsbG8=","token":"/+8","key":"NBSWY3DP"}`, row.String())
+
+	_, err = ti.CreateRow([]byte(`{"digest":"xyz"}`))
+	assert.ErrorIs(t, err, jsonline.ErrUnsupportedImportType)
+
+	format, rawtype, opts, err := jsonline.ParseDescriptor("binary[hex]")
+	assert.NoError(t, err)
+	assert.Equal(t, jsonline.Binary, format)
+	assert.Nil(t, rawtype)
+	assert.Equal(t, "binary[hex]", jsonline.Descriptor(format, rawtype, opts...))
+}
+
+func TestTemplateByteLayouts(t *testing.T) {
+	ti := jsonline.NewTemplate().
+		With("id", jsonline.Binary, int64(0), jsonline.ByteLayout(cast.BigEndian)).
+		With("delta", jsonline.Binary, int64(0), jsonline.Encoding(jsonline.Hex), jsonline.ByteLayout(cast.Varint))
+	to := jsonline.NewTemplate().
+		WithNumeric("id").
+		WithNumeric("delta")
+
+	row, err := ti.CreateRow([]byte(`{"id":"AAAAAAAAAQA=","delta":"d704"}`))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(256), row.GetOrNil("id"))
+	assert.Equal(t, int64(-300), row.GetOrNil("delta"))
+	assert.Equal(t, `{"id":"AAAAAAAAAQA=","delta":"d704"}`, row.String())
+
+	row, err = to.CreateRow(row)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"id":256,"delta":-300}`, row.String())
+
+	_, err = ti.CreateRow([]byte(`{"id":"AQA="}`))
+	assert.ErrorIs(t, err, jsonline.ErrUnsupportedImportType)
+
+	format, rawtype, opts, err := jsonline.ParseDescriptor("binary[hex](int64,network)")
+	assert.NoError(t, err)
+	assert.Equal(t, jsonline.Binary, format)
+	assert.Equal(t, int64(0), rawtype)
+	assert.Equal(t, "binary[hex](int64,be)", jsonline.Descriptor(format, rawtype, opts...))
+}
+
+func TestTemplateSemanticFormats(t *testing.T) {
+	ti := jsonline.NewTemplate().
+		With("id", jsonline.UUID, nil).
+		With("ip", jsonline.IPv4, netip.Addr{}).
+		With("ip6", jsonline.IPv6, nil).
+		With("network", jsonline.CIDR, nil).
+		With("site", jsonline.URL, nil).
+		With("mail", jsonline.Email, nil)
+
+	row, err := ti.CreateRow([]byte(`{"id":"{6BA7B810-9DAD-11D1-80B4-00C04FD430C8}","ip":"::ffff:192.168.0.1",` +
+		`"ip6":"2001:0db8:0000:0000:0000:0000:0000:0001","network":"10.0.0.0/8","site":"HTTPS://Example.COM/Path",` +
+		`"mail":"John.Doe@Example.COM"}`))
+	assert.NoError(t, err)
+	assert.Equal(t, "6ba7b810-9dad-11d1-80b4-00c04fd430c8", row.GetOrNil("id"))
+	assert.Equal(t, netip.MustParseAddr("192.168.0.1"), row.GetOrNil("ip"))
+	assert.Equal(t, `{"id":"6ba7b810-9dad-11d1-80b4-00c04fd430c8","ip":"192.168.0.1","ip6":"2001:db8::1",`+
+		`"network":"10.0.0.0/8","site":"https://example.com/Path","mail":"John.Doe@example.com"}`, row.String())
+
+	for _, line := range []string{
+		`{"id":"6ba7b810"}`,
+		`{"ip":"2001:db8::1"}`,
+		`{"ip6":"192.168.0.1"}`,
+		`{"network":"10.0.0.0"}`,
+		`{"site":"example.com"}`,
+		`{"mail":"john"}`,
+	} {
+		_, err = ti.CreateRow([]byte(line))
+		assert.ErrorIs(t, err, jsonline.ErrUnsupportedImportType, line)
+	}
+
+	format, rawtype, opts, err := jsonline.ParseDescriptor("uuid([16]byte)")
+	assert.NoError(t, err)
+	assert.Equal(t, jsonline.UUID, format)
+	assert.Equal(t, [16]byte{}, rawtype)
+	assert.Equal(t, "uuid([16]byte)", jsonline.Descriptor(format, rawtype, opts...))
+}
+
+func TestTemplateEmbeddedJSON(t *testing.T) {
+	ti := jsonline.NewTemplate().WithNumeric("id").With("payload", jsonline.JSON, nil)
+	to := jsonline.NewTemplate().WithNumeric("id").WithAuto("payload")
+
+	row, err := ti.CreateRow([]byte(`{"id":1,"payload":"{\"b\":2, \"a\":[1,{\"x\":true}]}"}`))
+	assert.NoError(t, err)
+	assert.Equal(t, json.Number("2"), row.GetAtPathOrNil("payload.b"))
+	assert.Equal(t, true, row.GetAtPathOrNil("payload.a[1].x"))
+	assert.Equal(t, `{"id":1,"payload":"{\"b\":2,\"a\":[1,{\"x\":true}]}"}`, row.String())
+
+	unwrapped, err := to.CreateRow(row)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"id":1,"payload":{"b":2,"a":[1,{"x":true}]}}`, unwrapped.String())
+
+	wrapped, err := ti.CreateRow(unwrapped)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"id":1,"payload":"{\"b\":2,\"a\":[1,{\"x\":true}]}"}`, wrapped.String())
+
+	_, err = ti.CreateRow([]byte(`{"payload":"{\"b\":"}`))
+	assert.ErrorIs(t, err, jsonline.ErrUnsupportedImportType)
+}
+
+func TestTemplateEmbeddedJSONWithTemplate(t *testing.T) {
+	embedded := jsonline.NewTemplate().WithMappedNumeric("amount", int64(0)).WithTimestamp("when")
+	ti := jsonline.NewTemplate().With("payload", jsonline.JSON, nil, jsonline.Embedded(embedded))
+
+	row, err := ti.CreateRow([]byte(`{"payload":"{\"when\":0,\"amount\":\"12\"}"}`))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(12), row.GetAtPathOrNil("payload.amount"))
+	assert.Equal(t, `{"payload":"{\"amount\":12,\"when\":0}"}`, row.String())
+
+	_, err = ti.CreateRow([]byte(`{"payload":"[1,2]"}`))
+	assert.ErrorIs(t, err, jsonline.ErrUnsupportedImportType)
+
+	column, ok := ti.Column("payload")
+	assert.True(t, ok)
+	assert.Equal(t, jsonline.JSON, column.Format)
+	assert.NotNil(t, column.Template)
+}
+
+func TestTemplateNullPolicies(t *testing.T) {
+	template := jsonline.NewTemplate().
+		With("id", jsonline.Numeric, nil, jsonline.OmitNull()).
+		With("name", jsonline.String, nil, jsonline.EmptyAsNull(), jsonline.NullIf("N/A")).
+		With("score", jsonline.Numeric, int64(0),
+			jsonline.NullIf(-1), jsonline.ReplaceNull("unknown"), jsonline.OmitNull()).
+		WithString("comment")
+
+	row, err := template.CreateRow([]byte(`{"id":null,"name":"","score":-1,"comment":""}`))
+	assert.NoError(t, err)
+	assert.Nil(t, row.GetOrNil("name"))
+	assert.Nil(t, row.GetOrNil("score"))
+	assert.Equal(t, "", row.GetOrNil("comment"))
+	assert.Equal(t, `{"name":null,"score":"unknown","comment":""}`, row.String())
+
+	row, err = template.CreateRow(map[string]interface{}{"id": 1, "name": "N/A", "score": 3})
+	assert.NoError(t, err)
+	assert.Equal(t, `{"id":1,"name":null,"score":3,"comment":null}`, row.String())
+}
+
+func TestTemplateArrayNullPolicies(t *testing.T) {
+	template := jsonline.NewTemplate().
+		WithArray("tags", jsonline.String, nil, jsonline.ReplaceNull("none"), jsonline.OmitNull()).
+		WithArray("codes", jsonline.Numeric, nil, jsonline.ReplaceNull([]interface{}{})).
+		WithArray("notes", jsonline.String, nil, jsonline.OmitNull())
+
+	row, err := template.CreateRow([]byte(`{"tags":null,"codes":null,"notes":null}`))
+	assert.NoError(t, err)
+	assert.Nil(t, row.GetOrNil("tags"))
+	assert.Equal(t, `{"tags":"none","codes":[]}`, row.String())
+
+	row, err = template.CreateRow([]byte(`{"tags":["a",null],"codes":[1],"notes":["b"]}`))
+	assert.NoError(t, err)
+	assert.Equal(t, `{"tags":["a","none"],"codes":[1],"notes":["b"]}`, row.String())
+
+	exported, err := jsonline.NewValueArray(nil, jsonline.String, nil, jsonline.ReplaceNull("none")).Export()
+	assert.NoError(t, err)
+	assert.Equal(t, "none", exported)
+}
+
+// siren is a custom format for french company identifiers (9 digits with a Luhn checksum).
+//
+//nolint:gochecknoglobals
+var siren, _ = jsonline.RegisterFormat("siren",
+	func(val interface{}) (interface{}, error) {
+		str := strings.ReplaceAll(fmt.Sprint(val), " ", "")
+		if !regexp.MustCompile(`^[0-9]{9}$`).MatchString(str) {
+			return nil, fmt.Errorf("%v is not a siren", val)
+		}
+
+		sum := 0
+		for i, digit := range str {
+			n := int(digit - '0')
+			if i%2 == 1 {
+				if n *= 2; n > 9 {
+					n -= 9
+				}
+			}
+
+			sum += n
+		}
+
+		if sum%10 != 0 {
+			return nil, fmt.Errorf("%v has an invalid checksum", val)
+		}
+
+		return str, nil
+	},
+	func(raw interface{}) (interface{}, error) {
+		str := fmt.Sprint(raw)
+
+		return str[0:3] + " " + str[3:6] + " " + str[6:9], nil
+	},
+)
+
+func TestTemplateCustomFormat(t *testing.T) {
+	template := jsonline.NewTemplate().With("company", siren, nil)
+
+	row, err := template.CreateRow([]byte(`{"company":"732829320"}`))
+	assert.NoError(t, err)
+	assert.Equal(t, "732829320", row.GetOrNil("company"))
+	assert.Equal(t, `{"company":"732 829 320"}`, row.String())
+
+	_, err = template.CreateRow([]byte(`{"company":"732829321"}`))
+	assert.ErrorIs(t, err, jsonline.ErrUnsupportedImportType)
+
+	format, rawtype, opts, err := jsonline.ParseDescriptor("siren(int64)")
+	assert.NoError(t, err)
+	assert.Equal(t, siren, format)
+	assert.Equal(t, int64(0), rawtype)
+	assert.Equal(t, "siren(int64)", jsonline.Descriptor(format, rawtype, opts...))
+
+	def, err := jsonline.ParseInlineDefinition(`{"company":"siren(int64):siren"}`)
+	assert.NoError(t, err)
+
+	ti, to, err := def.Templates()
+	assert.NoError(t, err)
+
+	row, err = ti.CreateRow([]byte(`{"company":"732 829 320"}`))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(732829320), row.GetOrNil("company"))
+
+	row, err = to.CreateRow(row)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"company":"732 829 320"}`, row.String())
+
+	_, err = jsonline.RegisterFormat("siren", nil, nil)
+	assert.ErrorIs(t, err, jsonline.ErrInvalidFormat)
+}
+
+//nolint:gochecknoglobals
+var _ = jsonline.RegisterRawType("CountryCode", countryCode(""))
+
+// countryCode is a domain type used as rawtype, it implements encoding.TextMarshaler and encoding.TextUnmarshaler.
+type countryCode string
+
+func (c countryCode) MarshalText() ([]byte, error) {
+	return []byte(c), nil
+}
+
+func (c *countryCode) UnmarshalText(text []byte) error {
+	if len(text) != 2 {
+		return fmt.Errorf("invalid country code %q", text)
+	}
+
+	*c = countryCode(strings.ToUpper(string(text)))
+
+	return nil
+}
+
+func TestTemplateCustomRawType(t *testing.T) {
+	def, err := jsonline.ParseInlineDefinition(`{"country":"string(CountryCode)"}`)
+	assert.NoError(t, err)
+
+	ti, _, err := def.Templates()
+	assert.NoError(t, err)
+
+	row, err := ti.CreateRow([]byte(`{"country":"fr"}`))
+	assert.NoError(t, err)
+	assert.Equal(t, countryCode("FR"), row.GetOrNil("country"))
+	assert.Equal(t, `{"country":"FR"}`, row.String())
+
+	_, err = ti.CreateRow([]byte(`{"country":"france"}`))
+	assert.ErrorIs(t, err, jsonline.ErrUnsupportedImportType)
+
+	column, _ := ti.Column("country")
+	assert.Equal(t, "string(CountryCode)", jsonline.Descriptor(column.Format, column.RawType, column.Options...))
+
+	err = jsonline.RegisterRawType("CountryCode", countryCode(""))
+	assert.ErrorIs(t, err, jsonline.ErrInvalidRawType)
+}
@@ -37,6 +37,7 @@
 import (
 	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/cgi-fr/jsonline/pkg/cast"
 )
@@ -47,17 +48,45 @@
 )
 
 const (
-	String    Format = iota // String representation, e.g. : "hello", "2.4", "true".
-	Numeric                 // Numeric (integer or decimal), e.g. : 2.4, 1.
-	Boolean                 // Boolean : true or false.
-	Binary                  // Binary representation encoded as base64.
-	Date                    // Date without zone info, e.g. : "2006-01-02".
-	DateTime                // DateTime as RFC3339, e.g. : "2006-01-02T15:04:05Z", "2006-01-02T15:04:05+07:00".
-	Timestamp               // Timestamp the number of seconds since 1970 ("UNIX time").
-	Auto                    // Auto columns have no specific format enforced.
-	Hidden                  // Hidden columns will not be exported in jsonline.
+	String         Format = iota // String representation, e.g. : "hello", "2.4", "true".
+	Numeric                      // Numeric (integer or decimal), e.g. : 2.4, 1.
+	Boolean                      // Boolean : true or false.
+	Binary                       // Binary representation encoded as base64 (by default).
+	Date                         // Date without zone info, e.g. : "2006-01-02".
+	DateTime                     // DateTime as RFC3339, e.g. : "2006-01-02T15:04:05Z", "2006-01-02T15:04:05+07:00".
+	Timestamp                    // Timestamp the number of seconds since 1970 ("UNIX time").
+	Auto                         // Auto columns have no specific format enforced.
+	Hidden                       // Hidden columns will not be exported in jsonline.
+	TimestampMilli               // TimestampMilli the number of milliseconds since 1970.
+	TimestampMicro               // TimestampMicro the number of microseconds since 1970.
+	TimestampNano                // TimestampNano the number of nanoseconds since 1970.
+	Decimal                      // Decimal exact number with an optional precision and scale, e.g. : 12.30.
+	Duration                     // Duration as a Go duration, an ISO 8601 duration or a number, e.g. : "1h30m0s".
+	UUID                         // UUID in canonical form, e.g. : "6ba7b810-9dad-11d1-80b4-00c04fd430c8".
+	IPv4                         // IPv4 address, e.g. : "192.168.0.1".
+	IPv6                         // IPv6 address in compressed form, e.g. : "2001:db8::1".
+	CIDR                         // CIDR IP prefix, e.g. : "192.168.0.0/16".
+	URL                          // URL absolute URL, e.g. : "https://example.com/path".
+	Email                        // Email address without display name, e.g. : "john@example.com".
+	JSON                         // JSON document embedded in a string, e.g. : "{\"a\":1}".
 )
 
+// epochUnit returns the unit of a timestamp format.
+func epochUnit(f Format) (time.Duration, bool) {
+	switch f { //nolint:exhaustive
+	case Timestamp:
+		return time.Second, true
+	case TimestampMilli:
+		return time.Millisecond, true
+	case TimestampMicro:
+		return time.Microsecond, true
+	case TimestampNano:
+		return time.Nanosecond, true
+	default:
+		return 0, false
+	}
+}
+
 type Value interface {
 	GetFormat() Format
 	GetRawType() RawType
@@ -75,106 +104,176 @@ type Value interface {
 }
 
 type value struct {
-	raw interface{}
-	f   Format
-	typ RawType
+	raw  interface{}
+	f    Format
+	typ  RawType
+	opts *valueOptions
 }
 
-func NewValue(v interface{}, f Format, rawtype RawType) Value {
+func NewValue(v interface{}, f Format, rawtype RawType, opts ...ValueOption) Value {
+	o := newOptions(opts)
+	if o.isNull(v) {
+		v = nil
+	}
+
 	r, err := cast.To(rawtype, v)
 	if err != nil {
 		r = v
 	}
 
 	return &value{
-		raw: r,
-		f:   f,
-		typ: rawtype,
+		raw:  r,
+		f:    f,
+		typ:  rawtype,
+		opts: o,
 	}
 }
 
-func NewValueNil(f Format, rawtype RawType) Value {
+func NewValueNil(f Format, rawtype RawType, opts ...ValueOption) Value {
 	return &value{
-		raw: nil,
-		f:   f,
-		typ: rawtype,
+		raw:  nil,
+		f:    f,
+		typ:  rawtype,
+		opts: newOptions(opts),
 	}
 }
 
 func NewValueAuto(v interface{}) Value {
 	return &value{
-		raw: v,
-		f:   Auto,
-		typ: nil,
+		raw:  v,
+		f:    Auto,
+		typ:  nil,
+		opts: nil,
 	}
 }
 
 func NewValueString(v interface{}) Value {
 	return &value{
-		raw: v,
-		f:   String,
-		typ: nil,
+		raw:  v,
+		f:    String,
+		typ:  nil,
+		opts: nil,
 	}
 }
 
 func NewValueNumeric(v interface{}) Value {
 	return &value{
-		raw: v,
-		f:   Numeric,
-		typ: nil,
+		raw:  v,
+		f:    Numeric,
+		typ:  nil,
+		opts: nil,
 	}
 }
 
 func NewValueBoolean(v interface{}) Value {
 	return &value{
-		raw: v,
-		f:   Boolean,
-		typ: nil,
+		raw:  v,
+		f:    Boolean,
+		typ:  nil,
+		opts: nil,
 	}
 }
 
 func NewValueBinary(v interface{}) Value {
 	return &value{
-		raw: v,
-		f:   Binary,
-		typ: nil,
+		raw:  v,
+		f:    Binary,
+		typ:  nil,
+		opts: nil,
 	}
 }
 
 func NewValueDate(v interface{}) Value {
 	return &value{
-		raw: v,
-		f:   Date,
-		typ: nil,
+		raw:  v,
+		f:    Date,
+		typ:  nil,
+		opts: nil,
 	}
 }
 
 func NewValueDateTime(v interface{}) Value {
 	return &value{
-		raw: v,
-		f:   DateTime,
-		typ: nil,
+		raw:  v,
+		f:    DateTime,
+		typ:  nil,
+		opts: nil,
 	}
 }
 
 func NewValueTimestamp(v interface{}) Value {
 	return &value{
-		raw: v,
-		f:   Timestamp,
-		typ: nil,
+		raw:  v,
+		f:    Timestamp,
+		typ:  nil,
+		opts: nil,
+	}
+}
+
+func NewValueTimestampMilli(v interface{}) Value {
+	return &value{
+		raw:  v,
+		f:    TimestampMilli,
+		typ:  nil,
+		opts: nil,
+	}
+}
+
+func NewValueTimestampMicro(v interface{}) Value {
+	return &value{
+		raw:  v,
+		f:    TimestampMicro,
+		typ:  nil,
+		opts: nil,
+	}
+}
+
+func NewValueTimestampNano(v interface{}) Value {
+	return &value{
+		raw:  v,
+		f:    TimestampNano,
+		typ:  nil,
+		opts: nil,
 	}
 }
 
 func NewValueHidden(v interface{}) Value {
 	return &value{
-		raw: v,
-		f:   Hidden,
-		typ: nil,
+		raw:  v,
+		f:    Hidden,
+		typ:  nil,
+		opts: nil,
 	}
 }
 
 func CloneValue(v Value) Value {
-	return NewValue(v.Raw(), v.GetFormat(), v.GetRawType())
+	switch typed := v.(type) {
+	case Row:
+		return CloneRow(typed)
+	case *array:
+		return typed.clone()
+	}
+
+	return NewValue(v.Raw(), v.GetFormat(), v.GetRawType(), valueOptionsOf(v)...)
+}
+
+// omitted returns true if the value is null and must not be exported in a row, a replacement of null takes precedence.
+func omitted(v Value) bool {
+	switch typed := v.(type) {
+	case *value:
+		return typed.raw == nil && typed.opts != nil && typed.opts.omitNull && typed.opts.nullAs == nil
+	case *array:
+		return typed.values == nil && typed.opts != nil && typed.opts.omitNull && typed.opts.nullAs == nil
+	}
+
+	return false
+}
+
+// setRaw replaces the raw value without any conversion.
+func setRaw(v Value, raw interface{}) {
+	if typed, ok := v.(*value); ok {
+		typed.raw = raw
+	}
 }
 
 func (v *value) GetFormat() Format {
@@ -191,7 +290,7 @@ func (v *value) Raw() interface{} {
 
 func (v *value) Export() (interface{}, error) {
 	if v.raw == nil {
-		return nil, nil
+		return v.opts.nullValue(), nil
 	}
 
 	switch v.f {
@@ -202,32 +301,52 @@ func (v *value) Export() (interface{}, error) {
 	case Boolean:
 		return exportToBool(v.raw)
 	case Binary:
-		return exportToBinary(v.raw)
+		return exportToBinary(v.raw, v.opts)
 	case Date:
-		return exportToDate(v.raw)
+		return exportToDate(v.raw, v.opts)
 	case DateTime:
-		return exportToDateTime(v.raw)
+		return exportToDateTime(v.raw, v.opts)
 	case Timestamp:
 		return exportToTimestamp(v.raw)
+	case TimestampMilli, TimestampMicro, TimestampNano:
+		unit, _ := epochUnit(v.f)
+
+		return exportToTimestampUnit(v.raw, unit)
+	case Decimal:
+		return exportToDecimal(v.raw, v.opts)
+	case Duration:
+		return exportToDuration(v.raw, v.opts)
+	case UUID, IPv4, IPv6, CIDR, URL, Email:
+		return exportToSemantic(v.raw, v.f)
+	case JSON:
+		return exportToJSON(v.raw, v.opts)
 	case Auto, Hidden:
 		return v.raw, nil
 	}
 
+	if _, ok := customFormats[v.f]; ok {
+		return exportToCustom(v.raw, v.f)
+	}
+
 	return nil, fmt.Errorf("%w: %#v", ErrUnsupportedFormat, v.f)
 }
 
 //nolint:cyclop
 func (v *value) Import(val interface{}) error {
-	if val == nil {
+	if v.opts.isNull(val) {
 		v.raw = nil
 
 		return nil
 	}
 
-	if value, ok := val.(Value); ok {
+	// rows imported in a JSON value are decoded documents
+	_, isRow := val.(Row)
+
+	if value, ok := val.(Value); ok && (v.f != JSON || !isRow) {
 		v.f = value.GetFormat()
 		v.raw = value.Raw()
 		v.typ = value.GetRawType()
+		v.opts = newOptions(valueOptionsOf(value))
 
 		return nil
 	}
@@ -242,17 +361,32 @@ func (v *value) Import(val interface{}) error {
 	case Boolean:
 		v.raw, err = importFromBoolean(val, v.typ)
 	case Binary:
-		v.raw, err = importFromBinary(val, v.typ)
+		v.raw, err = importFromBinary(val, v.typ, v.opts)
 	case Date:
-		v.raw, err = importFromDate(val, v.typ)
+		v.raw, err = importFromDate(val, v.typ, v.opts)
 	case DateTime:
-		v.raw, err = importFromDateTime(val, v.typ)
+		v.raw, err = importFromDateTime(val, v.typ, v.opts)
 	case Timestamp:
 		v.raw, err = importFromTimestamp(val, v.typ)
+	case TimestampMilli, TimestampMicro, TimestampNano:
+		unit, _ := epochUnit(v.f)
+		v.raw, err = importFromTimestampUnit(val, v.typ, unit)
+	case Decimal:
+		v.raw, err = importFromDecimal(val, v.typ, v.opts)
+	case Duration:
+		v.raw, err = importFromDuration(val, v.typ, v.opts)
+	case UUID, IPv4, IPv6, CIDR, URL, Email:
+		v.raw, err = importFromSemantic(val, v.typ, v.f)
+	case JSON:
+		v.raw, err = importFromJSON(val, v.typ, v.opts)
 	case Auto, Hidden:
 		v.raw, err = cast.To(v.typ, val)
 	default:
-		err = fmt.Errorf("%w: %#v", ErrUnsupportedFormat, v.f)
+		if _, ok := customFormats[v.f]; ok {
+			v.raw, err = importFromCustom(val, v.typ, v.f)
+		} else {
+			err = fmt.Errorf("%w: %#v", ErrUnsupportedFormat, v.f)
+		}
 	}
 
 	return err
@@ -689,6 +689,27 @@ func TestValueImportTimestamp(t *testing.T) {
 	}
 }
 
+func TestValueImportTimestampTime(t *testing.T) {
+	testdatas := []struct {
+		value    interface{}
+		expected interface{}
+	}{
+		{nil, nil},
+		{1136189045, time.Unix(1136189045, 0)},
+		{1136189045.5, time.Unix(1136189045, 500000000)},
+		{json.Number("1136189045.25"), time.Unix(1136189045, 250000000)},
+	}
+
+	for _, td := range testdatas {
+		t.Run(fmt.Sprintf("%#v", td.value), func(t *testing.T) {
+			value := jsonline.NewValueNil(jsonline.Timestamp, time.Time{})
+			err := value.Import(td.value)
+			assert.NoError(t, err)
+			assert.Equal(t, td.expected, value.Raw())
+		})
+	}
+}
+
 func TestValueFormatAuto(t *testing.T) {
 	testdatas := []interface{}{
 		nil,